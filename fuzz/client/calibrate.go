@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/shellrausch/gofuzzy/fuzz/opts"
+	"github.com/shellrausch/gofuzzy/fuzz/utils"
+)
+
+// calibrationPayloadLength is the length of a random payload. The calibration requests
+// use multiples of its half, see calibrationPayload.
+const calibrationPayloadLength = 16
+
+// calibrationPayload returns a random payload for the i-th calibration request. Every request
+// has a payload of a different length, so that a size which reflects the payload (e.g. a
+// "not found" page with the requested path) differs and is not learned.
+func calibrationPayload(i int) string {
+	return utils.RandomString(calibrationPayloadLength / 2 * (i + 1))
+}
+
+// Calibrate sends requests with random payloads, which certainly don't exist, for every extension.
+// If the target answers them all alike (e.g. with a soft-404 page), the learned baseline
+// is added to the hide filters. The learned filters are listed in o.CalibratedFilters.
+// The target is calibrated only once, also if it is called again.
+// In vhost mode the baseline of the vhost comparison is fetched instead and in DNS mode
+// a wildcard entry is detected, also with -nc.
+func (f *Fuzzer) Calibrate(ctx context.Context) {
+	o := f.o
+	if f.calibrated {
+		return
+	}
+	f.calibrated = true
+
+	if o.DNS {
+		f.detectWildcard(ctx)
+		return
+	}
+
+	if o.VHost {
+		f.fetchVHostBaseline(ctx)
+		return
+	}
+
+	if o.NoCalibration {
+		return
+	}
+
+	root := newBase(strings.TrimSuffix(o.URL.String(), "/"), "", 0, nil)
+	header := utils.SplitHeaderFields(o.CustomHeader, o.HeaderFieldSep)
+
+	for _, ext := range o.FileExtensions {
+		samples := []*Result{}
+		for i := 0; i < o.NumCalibrationRequests; i++ {
+			payload := map[string]string{}
+			for _, kw := range o.Wordlists.Keywords() {
+				payload[kw] = calibrationPayload(i)
+			}
+
+			res, err := f.invokeRequest(ctx, newRequest(o, root, header, payload, ext))
+			if ctx.Err() != nil {
+				return
+			}
+
+			if err != nil {
+				log.Printf("Calibration request failed: %s", err)
+				break
+			}
+			samples = append(samples, res)
+		}
+
+		if len(samples) == o.NumCalibrationRequests {
+			learnFilter(o, samples)
+		}
+	}
+}
+
+// learnFilter adds the most specific value, which all samples have in common, to the hide filters.
+// Since the payloads of the samples differ in length, a value which tracks the payload length is
+// never in common. If the bodies differ in every size, nothing is learned. Hiding the status
+// code or the header size instead would hide the real hits as well, since they are mostly
+// the same for all pages of a server.
+func learnFilter(o *opts.Opts, samples []*Result) {
+	// Nothing to learn, the baseline is already hidden (e.g. a regular 404).
+	if !isInFilter(o, samples[0]) {
+		return
+	}
+
+	baselines := []struct {
+		flag   string
+		filter *utils.Ranges
+		value  func(*Result) int
+	}{
+		{"-hh", &o.HTTPHideBodyLength, func(r *Result) int { return r.ContentLength }},
+		{"-hw", &o.HTTPHideNumWords, func(r *Result) int { return r.NumWords }},
+		{"-hl", &o.HTTPHideBodyLines, func(r *Result) int { return r.NumLines }},
+	}
+
+	for _, b := range baselines {
+		v := b.value(samples[0])
+
+		isStable := true
+		for _, r := range samples[1:] {
+			if b.value(r) != v {
+				isStable = false
+			}
+		}
+
+		if isStable {
+			b.filter.Add(v)
+			o.CalibratedFilters = append(o.CalibratedFilters, fmt.Sprintf("%s %d", b.flag, v))
+			return
+		}
+	}
+
+	log.Printf("Calibration: the responses to random payloads (status code %d) differ in chars, words and lines, no filter is learned. Hide them with a filter of your own", samples[0].StatusCode)
+}
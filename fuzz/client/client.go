@@ -3,17 +3,22 @@ package client
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"crypto/tls"
 	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/http/httptrace"
 	"net/http/httputil"
-	"os"
+	"net/url"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/shellrausch/gofuzzy/fuzz/dns"
 	"github.com/shellrausch/gofuzzy/fuzz/opts"
 	"github.com/shellrausch/gofuzzy/fuzz/utils"
 )
@@ -22,7 +27,30 @@ import (
 type ResultChannels struct {
 	Result   chan *Result
 	Progress chan *Progress
-	Finish   chan bool
+	Finish   chan *Summary
+}
+
+// Fuzzer runs a single scan with its own HTTP clients, rate limit and progress.
+// Fuzzers don't share any state, so several scans with different options can
+// run side by side in one process.
+type Fuzzer struct {
+	numResults uint64 // Accessed atomically. First for the 64-bit alignment on 32-bit platforms.
+
+	ResultChannels
+
+	o            *opts.Opts
+	httpClient   http.Client
+	replayClient *http.Client // Only set if a replay proxy is provided
+	limiter      *rateLimiter
+	thr          *throttle
+	mu           sync.Mutex // Guards rec, which is created when the scan starts
+	rec          *recursion
+	resumeStates []*BaseState
+	calibrated   bool
+	baseline     *vhostBaseline // Only set in vhost mode
+	resolver     *dns.Resolver  // Only set in DNS mode
+	wildcard     *dns.Wildcard  // Only set in DNS mode, if the domain has a wildcard entry
+	similar      *similarity    // Only set with -fs
 }
 
 // Result contains HTTP responses and results which are calculated
@@ -33,46 +61,106 @@ type Result struct {
 	StatusCode    int
 	NumLines      int
 	HeaderSize    int
-	Payload       string
+	TTFB          int               // Time to first byte in milliseconds
+	Duration      int               // Total time in milliseconds until the whole body was read
+	Payload       map[string]string // Keyword -> payload from the wordlist
+	Encoded       map[string]string // Keyword -> payload as sent, after the encoders. Only set if encoders are used.
+	Host          string            // Resolved hostname. Only set in DNS mode.
+	Records       []string          // DNS records of a live host, e.g. "A 10.0.0.1". Only set in DNS mode.
+	URL           string
+	BasePath      string    // Path of the (recursively) discovered directory the result was found under
+	Exchange      *Exchange `json:"-"` // Full request and response. Only set if o.CaptureExchanges is set.
+
+	dir    string // URL of the directory, if the response looks like a directory
+	body   []byte
+	header http.Header
+}
+
+// Summary contains the statistics of a finished or interrupted scan.
+type Summary struct {
+	NumDoneRequests uint
+	NumResults      uint
+	NumSimilar      uint // Results hidden as similar responses with -fs
+	Duration        time.Duration
+	Interrupted     bool
 }
 
 // Progress contains the actual progress information.
 type Progress struct {
 	NumDoneRequests   uint
 	NumApproxRequests uint
+	TotalUnknown      bool   // The number of requests is unknown, e.g. if the payloads are read from stdin
+	Throttle          string // State of the adaptive throttling, empty at full speed
 }
 
 // request contains all information needed to make a plain HTTP request.
 // This struct is just a stub.
 type request struct {
-	url     string
-	data    string
-	method  string
-	payload string
-	ext     string
-	retries uint8
-	header  map[string]string
+	url        string
+	data       string
+	method     string
+	payload    map[string]string // Encoded payloads, which are placed in the request
+	rawPayload map[string]string
+	ext        string
+	retries    uint8
+	header     map[string]string
+	base       *base
+
+	seq         uint64 // Sequence number of the request within its base
+	hitMutation bool   // Mutation of a hit after the main scan, see -mutate-hits
 }
 
-var httpClient http.Client
-var resultChs ResultChannels
+// New creates a fuzzer for an initialized option set and all public channels,
+// so that the caller can receive results on them. The options must not be shared
+// with another fuzzer, since they hold the progress of the scan.
+func New(o *opts.Opts) *Fuzzer {
+	f := &Fuzzer{
+		ResultChannels: ResultChannels{
+			Result:   make(chan *Result, o.Concurrency),
+			Progress: make(chan *Progress, o.Concurrency), // Just a buffer which is large enough
+			Finish:   make(chan *Summary, 1),
+		},
+		o:          o,
+		httpClient: initHTTPClient(o, o.Proxy),
+		limiter:    newRateLimiter(float64(o.Rate), o.Burst),
+	}
+	f.thr = newThrottle(o.Adaptive, o.Concurrency, f.limiter)
 
-// New initializes all public channels, so that the caller
-// can receive results on them.
-func New(o *opts.Opts) ResultChannels {
-	resultChs = ResultChannels{
-		Result:   make(chan *Result, o.Concurrency),
-		Progress: make(chan *Progress, o.Concurrency), // Just a buffer which is large enough
-		Finish:   make(chan bool),
+	if o.FilterSimilar != "" {
+		f.similar = newSimilarity(o.SimilarShown)
 	}
 
-	httpClient = initHTTPClient(o)
+	if o.DNS {
+		f.resolver = dns.New(o.Resolvers)
+		// Live hosts are requested at the addresses from the DNS servers of the scan.
+		f.httpClient.Transport.(*http.Transport).DialContext = f.resolver.DialContext
+	}
+
+	if o.ReplayProxy != nil {
+		c := initHTTPClient(o, o.ReplayProxy)
+		f.replayClient = &c
+	}
+
+	return f
+}
 
-	return resultChs
+// HandleRateSignals allows to adjust the rate of the scan with signals while it is running.
+func (f *Fuzzer) HandleRateSignals() {
+	handleRateSignals(f.limiter)
 }
 
-// Start starts the main fuzzing process for a given option set.
-func Start(o *opts.Opts) {
+// Run runs the main fuzzing process and blocks until it is finished. The results must be
+// received from the result channel, otherwise the scan blocks. Progress information
+// is dropped, if it is not received in time. If the target wasn't calibrated yet, it is
+// calibrated first.
+// If the context is canceled, no more requests are produced and all requests in flight
+// are canceled. Afterwards a summary is returned and sent on the finish channel as usual.
+func (f *Fuzzer) Run(ctx context.Context) *Summary {
+	o := f.o
+	start := time.Now()
+
+	f.Calibrate(ctx)
+
 	// We minimize the chance to be soft blocked by the filesystem as we will
 	// fetch more data at once (buffered channel), so the channel remains constantly filled.
 	queuedReqsCh := make(chan *request, o.Concurrency*o.Concurrency)
@@ -84,7 +172,17 @@ func Start(o *opts.Opts) {
 	// Synchronizes the number of Go routines which are provided with -t arg.
 	concurrencyWg := new(sync.WaitGroup)
 
-	go produceRequests(o, queuedReqsCh, producerDoneCh)
+	var rootState *BaseState
+	if len(f.resumeStates) > 0 {
+		rootState = f.resumeStates[0]
+	}
+
+	root := newBase(strings.TrimSuffix(o.URL.String(), "/"), "", 0, rootState)
+	f.mu.Lock()
+	f.rec = newRecursion(o, root, f.resumeStates)
+	f.mu.Unlock()
+
+	go f.produceRequests(ctx, root, queuedReqsCh, producerDoneCh)
 
 	for i := 0; i < o.Concurrency; i++ {
 		concurrencyWg.Add(1)
@@ -96,108 +194,341 @@ func Start(o *opts.Opts) {
 					concurrencyWg.Done()
 					return
 				}
-				consumeRequest(o, fuzzReq)
 
-				time.Sleep(o.Sleep)
+				// A canceled request is not marked as done, so a resumed scan sends it again.
+				if f.consumeRequest(ctx, fuzzReq) {
+					fuzzReq.base.done(fuzzReq)
+				}
+				f.rec.pending.Done()
+
+				sleep(ctx, o.Sleep)
 			}
 		}()
 	}
 
-	go produceProgress(o)
+	progressDoneCh := make(chan bool)
+	go f.produceProgress(progressDoneCh)
+
+	workersDoneCh := make(chan bool)
+	go f.thr.wakeOnCancel(ctx, workersDoneCh)
 
 	// Order matters for a proper termination of all Go routines.
 	<-producerDoneCh
 	close(queuedReqsCh)
 	concurrencyWg.Wait()
+	close(workersDoneCh)
+	close(progressDoneCh)
+
+	s := &Summary{
+		NumDoneRequests: uint(atomic.LoadUint64(&o.NumDoneRequests)),
+		NumResults:      uint(atomic.LoadUint64(&f.numResults)),
+		NumSimilar:      f.similar.hidden(),
+		Duration:        time.Since(start),
+		Interrupted:     ctx.Err() != nil,
+	}
+	f.Finish <- s
+	close(f.Result)
+	close(f.Finish)
 
-	resultChs.Finish <- true
-	close(resultChs.Result)
-	close(resultChs.Finish)
+	return s
 }
 
-// produceRequests reads a payload from the wordlist and produces a request-stub
-// with all relevant information to invoke a request.
-func produceRequests(o *opts.Opts, queuedReqsCh chan *request, producerDoneCh chan bool) {
-	fh, _ := os.Open(o.Wordlist)
-
-	url := strings.TrimSuffix(o.URL.String(), "/")
+// produceRequests combines the payloads of all wordlists and produces a request-stub
+// with all relevant information to invoke a request. With recursion enabled the wordlist
+// is produced again for every discovered directory.
+func (f *Fuzzer) produceRequests(ctx context.Context, root *base, queuedReqsCh chan *request, producerDoneCh chan bool) {
+	o := f.o
 	header := utils.SplitHeaderFields(o.CustomHeader, o.HeaderFieldSep)
 
-	s := bufio.NewScanner(fh)
-	for s.Scan() {
-		for _, ext := range o.FileExtensions {
-			queuedReqsCh <- &request{
-				method:  o.HTTPMethod,
-				url:     url,
-				header:  header,
-				data:    o.BodyData,
-				ext:     ext,
-				payload: s.Text(),
+	// queue queues a request. It reports false, if the context was canceled.
+	queue := func(r *request) bool {
+		f.rec.pending.Add(1)
+		select {
+		case queuedReqsCh <- r:
+			return true
+		case <-ctx.Done():
+			f.rec.pending.Done()
+			return false
+		}
+	}
+
+	for b := root; b != nil && ctx.Err() == nil; b = f.rec.next() {
+		if b.complete {
+			continue
+		}
+
+		producePayloads(ctx, o, func(payload map[string]string) {
+			for _, ext := range o.FileExtensions {
+				reqs := []*request{newRequest(o, b, header, payload, ext)}
+				if !o.MutateHits {
+					for _, v := range mutate(o, payload, ext) {
+						reqs = append(reqs, newRequest(o, b, header, v.Payload, v.Extension))
+					}
+				}
+
+				for _, r := range reqs {
+					// Already done in the resumed scan.
+					if !b.track(r) {
+						atomic.AddUint64(&o.NumDoneRequests, 1)
+						continue
+					}
+
+					if !queue(r) {
+						return
+					}
+				}
 			}
+		})
+
+		if ctx.Err() == nil {
+			b.finish()
 		}
 	}
 
-	fh.Close()
+	// All requests of the main scan are done, when the recursion is finished.
+	if o.MutateHits && ctx.Err() == nil {
+		f.produceHitMutations(header, queue)
+	}
+
 	producerDoneCh <- true
 }
 
+// newRequest creates a request stub for a payload and an extension below a base URL.
+func newRequest(o *opts.Opts, b *base, header map[string]string, payload map[string]string, ext string) *request {
+	return &request{
+		base:       b,
+		method:     o.HTTPMethod,
+		url:        b.url,
+		header:     header,
+		data:       o.BodyData,
+		ext:        ext,
+		payload:    encodePayload(o, payload),
+		rawPayload: payload,
+	}
+}
+
+// encodePayload applies the encoder chains of the keywords to their payloads.
+func encodePayload(o *opts.Opts, payload map[string]string) map[string]string {
+	if len(o.EncoderChains) == 0 {
+		return payload
+	}
+
+	encoded := map[string]string{}
+	for kw, p := range payload {
+		if chain, ok := o.EncoderChains[kw]; ok {
+			p = chain.Encode(p)
+		}
+		encoded[kw] = p
+	}
+
+	return encoded
+}
+
 // produceProgress produces progress information in a defined interval and
-// sends them via a channel.
-func produceProgress(o *opts.Opts) {
-	if o.ProgressOutput {
-		// No progress output until the whole wordlist was read.
-		// Otherwise we could get a division by zero in further progress calculation.
-		// Especially on huge wordlists this barrier is important.
-		<-o.WordlistReadComplete
-
-		tick := time.Tick(time.Millisecond * time.Duration(o.ProgressSendInterval))
-		for {
+// sends them via a channel, until the done channel is closed.
+func (f *Fuzzer) produceProgress(doneCh chan bool) {
+	o := f.o
+	if !o.ProgressOutput {
+		return
+	}
+
+	// No progress output until the whole wordlist was read.
+	// Otherwise we could get a division by zero in further progress calculation.
+	// Especially on huge wordlists this barrier is important.
+	select {
+	case <-o.WordlistReadComplete:
+	case <-doneCh:
+		return
+	}
+
+	ticker := time.NewTicker(time.Millisecond * time.Duration(o.ProgressSendInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p := &Progress{
+				NumDoneRequests:   uint(atomic.LoadUint64(&o.NumDoneRequests)),
+				NumApproxRequests: uint(atomic.LoadUint64(&o.NumApproxRequests)),
+				TotalUnknown:      o.UnknownNumRequests,
+				Throttle:          f.thr.state(),
+			}
+
+			// The progress is dropped, if nobody receives it.
 			select {
-			case <-tick:
-				resultChs.Progress <- &Progress{
-					NumDoneRequests:   o.NumDoneRequests,
-					NumApproxRequests: o.NumApproxRequests,
-				}
+			case f.Progress <- p:
+			default:
 			}
+		case <-doneCh:
+			return
 		}
 	}
 }
 
 // consumeRequest takes a given request stub and invokes the HTTP request.
-// If an error occurs the request is repeated a number of times
-// before the request is getting canceled.
-func consumeRequest(o *opts.Opts, r *request) {
-	res, err := invokeRequest(o, r)
+// If an error occurs or the target throttles us, the request is repeated a number
+// of times with an exponential backoff before the request is getting canceled.
+// It reports false, if the request was not completed because the context was canceled.
+func (f *Fuzzer) consumeRequest(ctx context.Context, r *request) bool {
+	o := f.o
+	for {
+		if !f.thr.acquire(ctx) {
+			return false
+		}
+		res, err := f.invoke(ctx, r)
+		f.thr.release()
 
-	o.NumDoneRequests++ // We don't care here for race conditions. It's just a nice to have progress value.
+		if ctx.Err() != nil {
+			return false
+		}
 
-	if err == nil && isInFilter(o, res) {
-		resultChs.Result <- res
-	}
+		atomic.AddUint64(&o.NumDoneRequests, 1)
 
-	if err != nil {
-		if r.retries < o.MaxRequestRetries {
+		throttled, retryAfter := f.thr.observe(res, err)
+
+		if (err != nil || throttled) && r.retries < o.MaxRequestRetries {
 			r.retries++
 
-			o.NumApproxRequests++ // We don't care for race conditions here. It's just a nice to have progress value.
+			atomic.AddUint64(&o.NumApproxRequests, 1)
+
+			if !sleep(ctx, backoff(r.retries, retryAfter)) {
+				return false
+			}
+			continue
+		}
 
-			consumeRequest(o, r)
-		} else {
+		if err != nil {
 			log.Printf("Giving up request. Too many errors: %s", err)
+			return true
 		}
+
+		if f.isHit(res) {
+			// The body is only needed by the filters. Results are kept by some output writers.
+			res.body = nil
+
+			if o.Recursive && res.dir != "" && !r.hitMutation {
+				f.rec.push(o, r.base, res.dir)
+			}
+
+			if o.MutateHits && !r.hitMutation {
+				r.base.addHit(r)
+			}
+
+			if f.replayClient != nil {
+				f.replayRequest(ctx, r)
+			}
+
+			atomic.AddUint64(&f.numResults, 1)
+			f.Result <- res
+		}
+
+		return true
 	}
 }
 
-// invokeRequest does the raw HTTP request. Before a HTTP request is finally done
+// invoke sends a request stub with the backend of the scan, which is DNS or HTTP.
+func (f *Fuzzer) invoke(ctx context.Context, r *request) (*Result, error) {
+	if f.resolver != nil {
+		return f.invokeLookup(ctx, r)
+	}
+
+	return f.invokeRequest(ctx, r)
+}
+
+// isHit reports if a result passes the filters. A live host of the DNS mode, which was
+// not requested over HTTP, is always a hit. Only results which pass all other filters
+// are grouped into similar responses.
+func (f *Fuzzer) isHit(res *Result) bool {
+	if f.resolver != nil && res.StatusCode == 0 {
+		return len(res.Records) > 0
+	}
+
+	return isInFilter(f.o, res) && f.baseline.differs(res) && f.similar.isNew(res)
+}
+
+// invokeRequest does the raw HTTP request and populates the result.
+// The request is canceled together with the context.
+func (f *Fuzzer) invokeRequest(ctx context.Context, r *request) (*Result, error) {
+	req, err := buildRequest(f.o, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	var ttfb time.Duration
+	trace := &httptrace.ClientTrace{
+		GotFirstResponseByte: func() { ttfb = time.Since(start) },
+	}
+	req = req.WithContext(httptrace.WithClientTrace(ctx, trace))
+
+	var reqBody string
+	if f.o.CaptureExchanges {
+		reqBody = requestBody(req)
+	}
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := populateResult(resp, r.rawPayload)
+	if f.o.CaptureExchanges {
+		result.Exchange = newExchange(req, reqBody, resp, result, start, f.o.MaxBodySize)
+	}
+	if len(f.o.EncoderChains) > 0 {
+		result.Encoded = r.payload
+	}
+	result.TTFB = int(ttfb / time.Millisecond)
+	result.Duration = int(time.Since(start) / time.Millisecond)
+	result.URL = req.URL.String()
+	result.BasePath = r.base.path
+	defer resp.Body.Close()
+
+	_, err = io.Copy(ioutil.Discard, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, err
+}
+
+// replayRequest sends a request, which passed the filters, once more through the replay proxy.
+// Hence the proxy history contains only the interesting requests.
+func (f *Fuzzer) replayRequest(ctx context.Context, r *request) {
+	req, err := buildRequest(f.o, r)
+	if err != nil {
+		return
+	}
+	req = req.WithContext(ctx)
+
+	resp, err := f.replayClient.Do(req)
+	if err != nil {
+		log.Printf("Unable to replay request: %s", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	io.Copy(ioutil.Discard, resp.Body)
+}
+
+// buildRequest creates the HTTP request from a request stub. Before a HTTP request is finally done
 // the first occurence of the FUZZ keyword will be replaced by a wordlist payload.
-func invokeRequest(o *opts.Opts, r *request) (*Result, error) {
+func buildRequest(o *opts.Opts, r *request) (*http.Request, error) {
 	var req *http.Request
 	var err error
 
+	if o.RawRequest != nil {
+		return buildRawRequest(o, r)
+	}
+
 	url := r.url
 	if !o.FuzzKeywordPresent {
-		r.payload = strings.TrimPrefix(r.payload, "/")
-		url = r.url + "/" + r.payload + r.ext
+		payload := strings.TrimPrefix(r.payload[o.FuzzKeyword], "/")
+		url = r.url + "/" + payload + r.ext
 	}
 
 	req, err = http.NewRequest(r.method, url, strings.NewReader(r.data))
@@ -218,6 +549,12 @@ func invokeRequest(o *opts.Opts, r *request) (*Result, error) {
 		req.Header.Set(h, v)
 	}
 
+	// Go ignores a Host header field and sends the host of the request instead.
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+		req.Header.Del("Host")
+	}
+
 	if o.FuzzKeywordPresent {
 		req, err = replaceFuzzKeyword(o, req, r)
 
@@ -226,66 +563,100 @@ func invokeRequest(o *opts.Opts, r *request) (*Result, error) {
 		}
 	}
 
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-
-	result := populateResult(resp, r.payload)
-	defer resp.Body.Close()
-
-	_, err = io.Copy(ioutil.Discard, resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	return result, err
+	return req, nil
 }
 
-// The FUZZ keyword can be everywhere in the HTTP request.
-// We replace the first occurency of the keyword FUZZ with a payload from the wordlist.
+// The keywords can be everywhere in the HTTP request.
+// We replace every keyword with its payload from the bound wordlist.
 func replaceFuzzKeyword(o *opts.Opts, req *http.Request, r *request) (*http.Request, error) {
-	reqBytes, _ := httputil.DumpRequest(req, true)
-
-	fuzzKeywordBytes := []byte(o.FuzzKeyword)
-	payloadBytes := []byte(r.payload)
+	replacer := payloadReplacer(r.payload)
 
-	// Replaces most of the FUZZ places in the request.
-	replaced := bytes.Replace(reqBytes, fuzzKeywordBytes, payloadBytes, -1)
 	// Go renames header fields automatically to the following format:
 	// "FUZZ: text/html" to "Fuzz: text/html".
-	// Therefore we make also an additional 'Fuzz' replacement.
-	replaced = bytes.Replace(replaced, bytes.Title(bytes.ToLower(fuzzKeywordBytes)), payloadBytes, -1)
+	// Therefore we make also an additional 'Fuzz' replacement in the header names.
+	req.Header = replaceHeaderNames(req.Header, r.payload)
+
+	reqBytes, _ := httputil.DumpRequest(req, true)
+
+	// Replaces most of the keyword places in the request.
+	replaced := replacer.Replace(string(reqBytes))
 
 	// Creates and validates a request from a textual (raw) request.
-	reqCopy, err := http.ReadRequest(bufio.NewReader(bytes.NewBuffer(replaced)))
+	reqCopy, err := http.ReadRequest(bufio.NewReader(strings.NewReader(replaced)))
 
 	// Replace extension.
-	ext := strings.Replace(r.ext, o.FuzzKeyword, r.payload, -1)
+	ext := replacer.Replace(r.ext)
 	// Replace URL.
-	url := strings.Replace(req.URL.String()+ext, o.FuzzKeyword, r.payload, -1)
+	url := replacer.Replace(req.URL.String() + ext)
 
 	if err != nil {
 		return nil, err
 	}
 
 	// Replace request body.
-	body := strings.Replace(r.data, o.FuzzKeyword, r.payload, -1)
+	body := replacer.Replace(r.data)
 
 	req, err = http.NewRequest(reqCopy.Method, url, strings.NewReader(body))
-	req.Header = reqCopy.Header
 
 	if err != nil {
 		return nil, err
 	}
 
+	req.Header = reqCopy.Header
+	req.Host = reqCopy.Host
+
 	return req, nil
 }
 
+// payloadReplacer creates a replacer which substitutes every keyword with its payload at once.
+// Hence a payload which contains another keyword is not replaced again.
+func payloadReplacer(payload map[string]string) *strings.Replacer {
+	oldnew := []string{}
+	for _, kw := range sortedKeywords(payload) {
+		oldnew = append(oldnew, kw, payload[kw])
+	}
+
+	return strings.NewReplacer(oldnew...)
+}
+
+// sortedKeywords returns the keywords of a payload, the longest first. A replacer tries the
+// keywords in this order, so that a keyword which contains another one is replaced as a whole.
+func sortedKeywords(payload map[string]string) []string {
+	kws := []string{}
+	for kw := range payload {
+		kws = append(kws, kw)
+	}
+
+	sort.Slice(kws, func(i, j int) bool {
+		if len(kws[i]) != len(kws[j]) {
+			return len(kws[i]) > len(kws[j])
+		}
+		return kws[i] < kws[j]
+	})
+
+	return kws
+}
+
+// replaceHeaderNames replaces the canonicalized keywords in the header names.
+func replaceHeaderNames(h http.Header, payload map[string]string) http.Header {
+	oldnew := []string{}
+	for _, kw := range sortedKeywords(payload) {
+		oldnew = append(oldnew, http.CanonicalHeaderKey(kw), payload[kw])
+	}
+	replacer := strings.NewReplacer(oldnew...)
+
+	header := http.Header{}
+	for name, values := range h {
+		header[replacer.Replace(name)] = values
+	}
+
+	return header
+}
+
 // populateResult creates the Result.
 // The Result is enriched with additional information which are
 // calculated at runtime, e.g. number of words/lines.
-func populateResult(resp *http.Response, payload string) *Result {
+func populateResult(resp *http.Response, payload map[string]string) *Result {
 	b, _ := ioutil.ReadAll(resp.Body)
 
 	// -1 indicates the length is unknown. Hence we count the body size manually.
@@ -294,6 +665,8 @@ func populateResult(resp *http.Response, payload string) *Result {
 		resp.ContentLength = int64(len(b))
 	}
 
+	dir, _ := discoveredDirectory(resp)
+
 	return &Result{
 		ContentLength: int(resp.ContentLength),
 		NumLines:      bytes.Count(b, []byte{'\n'}),
@@ -301,21 +674,56 @@ func populateResult(resp *http.Response, payload string) *Result {
 		HeaderSize:    utils.HeaderSize(resp.Header),
 		StatusCode:    resp.StatusCode,
 		Payload:       payload,
+		dir:           dir,
+		body:          b,
+		header:        resp.Header,
 	}
 }
 
 // isInFilter determines if result values, sizes, lengths, etc. should be filtered.
+// The show filters are applied first: a result must match every given show filter.
+// Afterwards a result is dropped, if it matches any hide filter.
 func isInFilter(o *opts.Opts, res *Result) bool {
-	return !o.HTTPHideCodes[res.StatusCode] &&
-		!o.HTTPHideBodyLength[res.ContentLength] &&
-		!o.HTTPHideNumWords[res.NumWords] &&
-		!o.HTTPHideBodyLines[res.NumLines] &&
-		!o.HTTPHideHeaderLength[res.HeaderSize]
+	return isShown(o.HTTPShowCodes, res.StatusCode) &&
+		isShown(o.HTTPShowBodyLength, res.ContentLength) &&
+		isShown(o.HTTPShowNumWords, res.NumWords) &&
+		isShown(o.HTTPShowBodyLines, res.NumLines) &&
+		isShown(o.HTTPShowHeaderLength, res.HeaderSize) &&
+		isShown(o.ShowDuration, res.Duration) &&
+		(o.BodyMatchRegex == nil || o.BodyMatchRegex.Match(res.body)) &&
+		(o.HeaderMatchRegex == nil || o.HeaderMatchRegex.MatchHeader(res.header)) &&
+		!o.HTTPHideCodes.Contains(res.StatusCode) &&
+		!o.HTTPHideBodyLength.Contains(res.ContentLength) &&
+		!o.HTTPHideNumWords.Contains(res.NumWords) &&
+		!o.HTTPHideBodyLines.Contains(res.NumLines) &&
+		!o.HTTPHideHeaderLength.Contains(res.HeaderSize) &&
+		!o.HideDuration.Contains(res.Duration) &&
+		(o.BodyFilterRegex == nil || !o.BodyFilterRegex.Match(res.body)) &&
+		(o.HeaderFilterRegex == nil || !o.HeaderFilterRegex.MatchHeader(res.header))
+}
+
+// isShown checks if a value matches a show filter. An empty show filter matches everything.
+func isShown(filter utils.Ranges, v int) bool {
+	return len(filter) == 0 || filter.Contains(v)
 }
 
 // initHTTPClient initialises the default HTTP client with fundamental
-// connection options for every request.
-func initHTTPClient(o *opts.Opts) http.Client {
+// connection options for every request. If a proxy is provided,
+// every request is sent through it (http, https and socks5 are supported).
+func initHTTPClient(o *opts.Opts, proxy *url.URL) http.Client {
+	transport := &http.Transport{
+		// Ignore invalid certs by default, since we are interested in the content.
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+			// The server name is taken from the URL if empty, and not from the Host header.
+			ServerName: o.SNI,
+		},
+	}
+
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
 	return http.Client{
 		Timeout: time.Duration(o.Timeout) * time.Millisecond,
 		// Do not follow redirects (HTTP status codes 30x).
@@ -325,11 +733,24 @@ func initHTTPClient(o *opts.Opts) http.Client {
 			}
 			return nil
 		},
-		// Ignore invalid certs by default, since we are interested in the content.
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-		},
+		Transport: transport,
+	}
+}
+
+// sleep pauses for a duration or until the context is canceled.
+// It reports false, if the context was canceled.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
 	}
 }
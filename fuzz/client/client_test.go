@@ -0,0 +1,25 @@
+package client
+
+import "testing"
+
+func TestPayloadReplacer(t *testing.T) {
+	tests := []struct {
+		payload map[string]string
+		in      string
+		want    string
+	}{
+		{map[string]string{"FUZZ": "u1"}, "/FUZZ", "/u1"},
+		{map[string]string{"FUZZ": "u1", "XFUZZ": "p1"}, "/FUZZ/XFUZZ", "/u1/p1"},
+		{map[string]string{"FUZZ": "u1", "FUZZ2": "p1"}, "/FUZZ/FUZZ2", "/u1/p1"},
+		{map[string]string{"A": "1", "AB": "2", "ABC": "3"}, "ABC-AB-A", "3-2-1"},
+	}
+
+	for _, tt := range tests {
+		// The map order is random, hence every payload is replaced a few times.
+		for i := 0; i < 20; i++ {
+			if got := payloadReplacer(tt.payload).Replace(tt.in); got != tt.want {
+				t.Fatalf("Replace(%s) with %v = %s, want %s", tt.in, tt.payload, got, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"log"
+	"net"
+	"time"
+
+	"github.com/shellrausch/gofuzzy/fuzz/utils"
+)
+
+// invokeLookup resolves the hostname of a request stub. Hosts which don't exist or
+// resolve to the wildcard entry of the domain have no records. With -dns-http a live
+// host is requested once over HTTP afterwards, at the path of the URL. If the HTTP request
+// fails, only the records are kept.
+func (f *Fuzzer) invokeLookup(ctx context.Context, r *request) (*Result, error) {
+	o := f.o
+	if err := f.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	host := payloadReplacer(r.payload).Replace(o.DNSHost)
+
+	lookupCtx, cancel := context.WithTimeout(ctx, time.Duration(o.Timeout)*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	answer, err := f.resolver.Lookup(lookupCtx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{
+		Payload:  r.rawPayload,
+		URL:      host,
+		Host:     host,
+		BasePath: r.base.path,
+		Duration: int(time.Since(start) / time.Millisecond),
+	}
+	if len(o.EncoderChains) > 0 {
+		res.Encoded = r.payload
+	}
+
+	if answer == nil || f.wildcard.Matches(answer) {
+		return res, nil
+	}
+	res.Records = answer.Records()
+
+	if o.DNSHTTP == "" {
+		return res, nil
+	}
+
+	// The host keeps the port and the path of the URL.
+	u := *o.URL
+	u.Scheme, u.Host = o.DNSHTTP, host
+	if port := o.URL.Port(); port != "" {
+		u.Host = net.JoinHostPort(host, port)
+	}
+
+	httpReq := newRequest(o, r.base, r.header, r.rawPayload, "")
+	httpReq.url = u.String()
+
+	httpRes, err := f.invokeRequest(ctx, httpReq)
+	if err != nil {
+		return res, nil
+	}
+	httpRes.Host, httpRes.Records = res.Host, res.Records
+
+	return httpRes, nil
+}
+
+// detectWildcard resolves random hostnames. If they resolve, the domain has a wildcard entry
+// and hosts which resolve to it are not live. The wildcard is listed in o.CalibratedFilters.
+func (f *Fuzzer) detectWildcard(ctx context.Context) {
+	o := f.o
+
+	hosts := []string{}
+	for i := 0; i < o.NumCalibrationRequests; i++ {
+		payload := map[string]string{}
+		for _, kw := range o.Wordlists.Keywords() {
+			payload[kw] = utils.RandomString(calibrationPayloadLength)
+		}
+		hosts = append(hosts, payloadReplacer(payload).Replace(o.DNSHost))
+	}
+
+	w, err := f.resolver.DetectWildcard(ctx, hosts)
+	if ctx.Err() != nil {
+		return
+	}
+
+	if err != nil {
+		log.Printf("Unable to detect wildcard DNS: %s", err)
+		return
+	}
+
+	if w != nil {
+		f.wildcard = w
+		o.CalibratedFilters = append(o.CalibratedFilters, w.String())
+	}
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// Exchange contains the full request and response of a result, e.g. for the HAR output.
+// It is only captured if o.CaptureExchanges is set. The response body is cut at -max-body.
+type Exchange struct {
+	Start          time.Time
+	Method         string
+	URL            string
+	Proto          string
+	RequestHeader  http.Header
+	RequestBody    string
+	ResponseProto  string
+	Status         string // Status line without the protocol, e.g. "200 OK"
+	ResponseHeader http.Header
+	ResponseBody   []byte
+	BodySize       int // Size of the whole response body
+}
+
+// requestBody returns a copy of the body of a request, which is not sent yet.
+func requestBody(req *http.Request) string {
+	if req.GetBody == nil {
+		return ""
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+
+	b, _ := ioutil.ReadAll(body)
+
+	return string(b)
+}
+
+func newExchange(req *http.Request, reqBody string, resp *http.Response, res *Result, start time.Time, maxBodySize int) *Exchange {
+	// Go sends the Host header separately from the other header fields.
+	header := http.Header{"Host": {req.Host}}
+	if req.Host == "" {
+		header.Set("Host", req.URL.Host)
+	}
+	for name, values := range req.Header {
+		header[name] = values
+	}
+	if _, ok := header["User-Agent"]; !ok {
+		header.Set("User-Agent", "Go-http-client/1.1") // Default of the Go HTTP client
+	}
+
+	body := res.body
+	if len(body) > maxBodySize {
+		// A copy, so that the whole body is not kept in memory together with the result.
+		body = append([]byte(nil), body[:maxBodySize]...)
+	}
+
+	return &Exchange{
+		Start:          start,
+		Method:         req.Method,
+		URL:            req.URL.String(),
+		Proto:          req.Proto,
+		RequestHeader:  header,
+		RequestBody:    reqBody,
+		ResponseProto:  resp.Proto,
+		Status:         resp.Status,
+		ResponseHeader: resp.Header,
+		ResponseBody:   body,
+		BodySize:       len(res.body),
+	}
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"sync/atomic"
+
+	"github.com/shellrausch/gofuzzy/fuzz/opts"
+)
+
+// Hit is a result, whose payload is mutated after the main scan with -mutate-hits.
+type Hit struct {
+	Payload   map[string]string // Raw payloads
+	Extension string
+}
+
+// mutate creates the variants of the payload of the first wordlist with the mutation rules.
+// If the payload is appended to the URL, the extension is mutated together with it,
+// so that e.g. backup + .php becomes backup.php~ instead of backup~.php.
+// An empty payload (e.g. while another keyword is fuzzed in sniper mode) is not mutated.
+func mutate(o *opts.Opts, payload map[string]string, ext string) []Hit {
+	word := payload[o.FuzzKeyword]
+	if word == "" {
+		return nil
+	}
+
+	if !o.FuzzKeywordPresent {
+		word, ext = word+ext, ""
+	}
+
+	variants := []Hit{}
+	for _, v := range o.MutationRules.Mutate(word) {
+		p := map[string]string{}
+		for kw, pl := range payload {
+			p[kw] = pl
+		}
+		p[o.FuzzKeyword] = v
+
+		variants = append(variants, Hit{Payload: p, Extension: ext})
+	}
+
+	return variants
+}
+
+// addHit remembers a result of the base for the mutation after the main scan.
+func (b *base) addHit(r *request) {
+	b.Lock()
+	b.hits = append(b.hits, Hit{Payload: r.rawPayload, Extension: r.ext})
+	b.Unlock()
+}
+
+// produceHitMutations produces the mutations of all hits of all bases after the main scan.
+// The mutations are not tracked for a resumed scan. If the scan is interrupted now, all
+// hits are mutated again.
+func (f *Fuzzer) produceHitMutations(header map[string]string, queue func(*request) bool) {
+	f.rec.Lock()
+	bases := append([]*base{}, f.rec.all...)
+	f.rec.Unlock()
+
+	for _, b := range bases {
+		b.Lock()
+		hits := append([]Hit{}, b.hits...)
+		b.Unlock()
+
+		for _, h := range hits {
+			for _, v := range mutate(f.o, h.Payload, h.Extension) {
+				r := newRequest(f.o, b, header, v.Payload, v.Extension)
+				r.hitMutation = true
+
+				atomic.AddUint64(&f.o.NumApproxRequests, 1)
+				if !queue(r) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"log"
+
+	"github.com/shellrausch/gofuzzy/fuzz/generator"
+	"github.com/shellrausch/gofuzzy/fuzz/opts"
+)
+
+// producePayloads combines the payloads of all wordlists according to the attack mode.
+// Every combination is passed as a keyword -> payload map to emit.
+// Producing stops as soon as the context is canceled.
+func producePayloads(ctx context.Context, o *opts.Opts, emit func(map[string]string)) {
+	switch o.Mode {
+	case opts.ModeSniper:
+		produceSniper(ctx, o.Wordlists, emit)
+	case opts.ModePitchfork:
+		producePitchfork(ctx, o.Wordlists, emit)
+	default:
+		produceClusterbomb(ctx, o.Wordlists, map[string]string{}, emit)
+	}
+}
+
+// produceSniper fuzzes one keyword after the other, all other keywords are left empty.
+func produceSniper(ctx context.Context, wls opts.Wordlists, emit func(map[string]string)) {
+	for _, wl := range wls {
+		scanWordlist(ctx, wl, func(line string) {
+			payload := map[string]string{}
+			for _, kw := range wls.Keywords() {
+				payload[kw] = ""
+			}
+			payload[wl.Keyword] = line
+
+			emit(payload)
+		})
+	}
+}
+
+// producePitchfork zips the lines of all wordlists. It stops with the shortest wordlist.
+func producePitchfork(ctx context.Context, wls opts.Wordlists, emit func(map[string]string)) {
+	iterators := []generator.Iterator{}
+	for _, wl := range wls {
+		it, err := wl.Open()
+		if err != nil {
+			log.Printf("Unable to open wordlist: %s", err)
+			return
+		}
+		defer it.Close()
+
+		iterators = append(iterators, it)
+	}
+
+	for ctx.Err() == nil {
+		payload := map[string]string{}
+		for i, it := range iterators {
+			line, ok := it.Next()
+			if !ok {
+				return
+			}
+			payload[wls[i].Keyword] = line
+		}
+
+		emit(payload)
+	}
+}
+
+// produceClusterbomb tries every combination of all wordlists. The wordlists are
+// read again for every line of the preceding wordlist, so nothing is held in memory.
+func produceClusterbomb(ctx context.Context, wls opts.Wordlists, payload map[string]string, emit func(map[string]string)) {
+	if len(wls) == 0 {
+		// Every receiver gets its own copy, because the map is reused for the next combination.
+		combination := map[string]string{}
+		for kw, p := range payload {
+			combination[kw] = p
+		}
+
+		emit(combination)
+		return
+	}
+
+	scanWordlist(ctx, wls[0], func(line string) {
+		payload[wls[0].Keyword] = line
+		produceClusterbomb(ctx, wls[1:], payload, emit)
+	})
+}
+
+// scanWordlist calls fn for every payload of the wordlist or generator, until the context is canceled.
+func scanWordlist(ctx context.Context, wl *opts.Wordlist, fn func(string)) {
+	it, err := wl.Open()
+	if err != nil {
+		log.Printf("Unable to open wordlist: %s", err)
+		return
+	}
+	defer it.Close()
+
+	for ctx.Err() == nil {
+		line, ok := it.Next()
+		if !ok {
+			return
+		}
+		fn(line)
+	}
+}
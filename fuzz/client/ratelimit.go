@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket which is shared by all workers of a scan. It limits the
+// number of requests per second, independent of the concurrency level. The adaptive
+// throttling scales the base rate down, changes of the base rate (e.g. by signals) are kept.
+type rateLimiter struct {
+	sync.Mutex
+	base   float64 // Requests per second as set with -rate or by signals. 0 means unlimited.
+	scale  float64 // Share of the base rate, which is used
+	rate   float64 // Tokens per second, the base rate multiplied by the scale
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		base:   rate,
+		scale:  1,
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a token is available and takes it.
+// It returns the error of the context, if it was canceled in the meantime.
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	l.Lock()
+	if l.rate <= 0 {
+		l.Unlock()
+		return ctx.Err()
+	}
+
+	l.refill()
+
+	// The token is reserved right away, even if it is not available yet.
+	// Hence waiting workers are served in order and the bucket can get negative.
+	l.tokens--
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.Unlock()
+
+	if !sleep(ctx, wait) {
+		return ctx.Err()
+	}
+
+	return nil
+}
+
+// SetRate changes the base rate while the scan is running.
+func (l *rateLimiter) SetRate(rate float64) {
+	l.Lock()
+	defer l.Unlock()
+
+	l.refill()
+	l.base = rate
+	l.rate = l.base * l.scale
+}
+
+// SetScale sets the share of the base rate, which is used. 1 is the full base rate.
+func (l *rateLimiter) SetScale(scale float64) {
+	l.Lock()
+	defer l.Unlock()
+
+	l.refill()
+	l.scale = scale
+	l.rate = l.base * l.scale
+}
+
+// Rate returns the base rate in requests per second.
+func (l *rateLimiter) Rate() float64 {
+	l.Lock()
+	defer l.Unlock()
+
+	return l.base
+}
+
+// refill adds the tokens which accrued since the last refill. Must be called with the lock held.
+func (l *rateLimiter) refill() {
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+}
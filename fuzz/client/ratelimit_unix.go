@@ -0,0 +1,38 @@
+//go:build !windows
+// +build !windows
+
+package client
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// handleRateSignals adjusts the rate while the scan is running.
+// SIGUSR1 halves the rate, SIGUSR2 doubles it.
+// Example: kill -USR1 $(pidof gofuzzy)
+func handleRateSignals(l *rateLimiter) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		for sig := range sigs {
+			rate := l.Rate()
+			if rate <= 0 {
+				log.Printf("No rate limit set with -rate, the rate can't be adjusted")
+				continue
+			}
+
+			if sig == syscall.SIGUSR1 {
+				rate /= 2
+			} else {
+				rate *= 2
+			}
+
+			l.SetRate(rate)
+			log.Printf("Rate limit set to %.2f req/s", rate)
+		}
+	}()
+}
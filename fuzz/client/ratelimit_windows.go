@@ -0,0 +1,4 @@
+package client
+
+// handleRateSignals is a no-op, since Windows has no user defined signals.
+func handleRateSignals(l *rateLimiter) {}
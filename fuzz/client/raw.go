@@ -0,0 +1,47 @@
+package client
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/shellrausch/gofuzzy/fuzz/opts"
+)
+
+// buildRawRequest creates the HTTP request from the raw request template. The keywords are
+// replaced in the raw text before it is parsed, so every part of the request is an injection
+// point: the request line, the Host header, all other headers and the body.
+func buildRawRequest(o *opts.Opts, r *request) (*http.Request, error) {
+	replacer := payloadReplacer(r.payload)
+
+	req, body, err := opts.ParseRawRequest(replacer.Replace(string(o.RawRequest)))
+	if err != nil {
+		return nil, err
+	}
+
+	// A request read from a text is a server request, which must be converted to a client request.
+	req.RequestURI = ""
+	if !req.URL.IsAbs() {
+		req.URL.Scheme = o.RequestProto
+		req.URL.Host = req.Host
+	}
+
+	req.Body = ioutil.NopCloser(strings.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) { return ioutil.NopCloser(strings.NewReader(body)), nil }
+	req.ContentLength = int64(len(body))
+
+	if o.UserAgent != "" {
+		req.Header.Set("User-Agent", replacer.Replace(o.UserAgent))
+	}
+
+	if o.Cookie != "" {
+		req.Header.Set("Cookie", replacer.Replace(o.Cookie))
+	}
+
+	for h, v := range r.header {
+		req.Header.Set(replacer.Replace(h), replacer.Replace(v))
+	}
+
+	return req, nil
+}
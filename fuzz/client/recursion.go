@@ -0,0 +1,150 @@
+package client
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/shellrausch/gofuzzy/fuzz/opts"
+)
+
+// base is a URL under which the payloads of the wordlist are fuzzed.
+type base struct {
+	sync.Mutex
+	url   string
+	path  string // Relative to the target URL, e.g. "admin/". Empty for the target URL itself.
+	depth int
+
+	// Progress of the base, see BaseState.
+	produced   uint64
+	complete   bool
+	pending    map[uint64]*request
+	resume     *BaseState
+	resumeDone map[uint64]bool
+	hits       []Hit
+}
+
+// newBase creates a base. If a saved progress is given, the base continues from it.
+func newBase(url, path string, depth int, resume *BaseState) *base {
+	b := &base{url: url, path: path, depth: depth, pending: map[uint64]*request{}}
+
+	if resume != nil {
+		b.resume = resume
+		b.complete = resume.Finished
+		b.hits = resume.Hits
+		b.resumeDone = map[uint64]bool{}
+		for _, seq := range resume.Done {
+			b.resumeDone[seq] = true
+		}
+	}
+
+	return b
+}
+
+// recursion keeps track of the directories which were discovered during the scan.
+// Every new directory is queued as a base, so that the wordlist is fuzzed again below it.
+type recursion struct {
+	sync.Mutex
+	visited map[string]bool
+	queue   []*base
+	all     []*base // All bases in the order they were discovered
+	// Counts all queued requests which are not finished yet. Only a finished
+	// request can discover a new directory.
+	pending sync.WaitGroup
+}
+
+// newRecursion creates the recursion state with the target URL as the first base.
+// The bases of a resumed scan are queued again, unless they are finished.
+func newRecursion(o *opts.Opts, root *base, resumed []*BaseState) *recursion {
+	rc := &recursion{visited: map[string]bool{root.url: true}, all: []*base{root}}
+
+	for _, s := range resumed {
+		if rc.visited[s.URL] {
+			continue
+		}
+		rc.visited[s.URL] = true
+
+		b := newBase(s.URL, s.Path, s.Depth, s)
+		rc.all = append(rc.all, b)
+
+		if !s.Finished {
+			rc.queue = append(rc.queue, b)
+			o.AddBase()
+		}
+	}
+
+	return rc
+}
+
+// push queues a discovered directory, if it is new and the depth limit is not exceeded.
+func (rc *recursion) push(o *opts.Opts, parent *base, dirURL string) {
+	dirURL = strings.TrimSuffix(dirURL, "/")
+	if parent.depth+1 > o.RecursionDepth || !strings.HasPrefix(dirURL, parent.url+"/") {
+		return
+	}
+
+	rc.Lock()
+	defer rc.Unlock()
+
+	if rc.visited[dirURL] {
+		return
+	}
+	rc.visited[dirURL] = true
+
+	b := newBase(dirURL, parent.path+strings.TrimPrefix(dirURL, parent.url+"/")+"/", parent.depth+1, nil)
+	rc.queue = append(rc.queue, b)
+	rc.all = append(rc.all, b)
+
+	o.AddBase()
+}
+
+// next waits until the queue holds a base or until no pending request can discover a new one.
+// It returns nil if the recursion is finished.
+func (rc *recursion) next() *base {
+	for {
+		rc.Lock()
+		if len(rc.queue) > 0 {
+			b := rc.queue[0]
+			rc.queue = rc.queue[1:]
+			rc.Unlock()
+			return b
+		}
+		rc.Unlock()
+
+		rc.pending.Wait()
+
+		rc.Lock()
+		empty := len(rc.queue) == 0
+		rc.Unlock()
+
+		if empty {
+			return nil
+		}
+	}
+}
+
+// discoveredDirectory returns the URL of a directory, if the response looks like one.
+// This is a redirect to the requested path with a trailing slash (e.g. /admin -> /admin/)
+// or a 200/403 on a path with a trailing slash.
+func discoveredDirectory(resp *http.Response) (string, bool) {
+	reqURL := resp.Request.URL
+
+	switch resp.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		loc, err := resp.Location()
+		if err != nil || loc.Host != reqURL.Host || loc.Path != reqURL.Path+"/" {
+			return "", false
+		}
+
+		return reqURL.Scheme + "://" + reqURL.Host + loc.Path, true
+	case http.StatusOK, http.StatusForbidden:
+		if !strings.HasSuffix(reqURL.Path, "/") {
+			return "", false
+		}
+
+		return reqURL.Scheme + "://" + reqURL.Host + reqURL.Path, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"hash/fnv"
+	"html"
+	"math/bits"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// maxSimilarityDistance is the maximum number of different bits of two similarity hashes,
+// which belong to the same cluster.
+const maxSimilarityDistance = 3
+
+// similarity groups responses into clusters of similar responses with -fs. A response is
+// similar to another one, if it has the same status code and its body has almost the same
+// similarity hash. The payload is stripped from the body before, so a page which echoes the
+// payload is similar for all payloads. The first results of a cluster are shown, after a
+// number of repeats the cluster is represented by them and further results are hidden.
+type similarity struct {
+	numHidden uint64 // Accessed atomically. First for the 64-bit alignment on 32-bit platforms.
+
+	sync.Mutex
+	maxShown int
+	clusters map[int][]*cluster // Status code -> clusters
+}
+
+type cluster struct {
+	hash  uint64
+	count int
+}
+
+func newSimilarity(maxShown int) *similarity {
+	return &similarity{maxShown: maxShown, clusters: map[int][]*cluster{}}
+}
+
+// isNew adds a response to its cluster and reports, if it is shown as result.
+// Without -fs every response is new.
+func (s *similarity) isNew(res *Result) bool {
+	if s == nil {
+		return true
+	}
+
+	h := simhash(strings.Fields(stripPayload(string(res.body), res)))
+
+	s.Lock()
+	defer s.Unlock()
+
+	var c *cluster
+	for _, candidate := range s.clusters[res.StatusCode] {
+		if isSimilar(candidate.hash, h) {
+			c = candidate
+			break
+		}
+	}
+
+	if c == nil {
+		c = &cluster{hash: h}
+		s.clusters[res.StatusCode] = append(s.clusters[res.StatusCode], c)
+	}
+	c.count++
+
+	if c.count > s.maxShown {
+		atomic.AddUint64(&s.numHidden, 1)
+		return false
+	}
+
+	return true
+}
+
+// hidden returns the number of hidden similar responses.
+func (s *similarity) hidden() uint {
+	if s == nil {
+		return 0
+	}
+
+	return uint(atomic.LoadUint64(&s.numHidden))
+}
+
+// isSimilar reports if two similarity hashes belong to the same cluster.
+func isSimilar(a, b uint64) bool {
+	return bits.OnesCount64(a^b) <= maxSimilarityDistance
+}
+
+// stripPayload removes the payloads from a body, as they are sent and as they are
+// commonly echoed: raw, URL encoded and HTML escaped.
+func stripPayload(body string, res *Result) string {
+	payloads := []string{}
+	for kw, p := range res.Payload {
+		payloads = append(payloads, p, url.QueryEscape(p), url.PathEscape(p), html.EscapeString(p), res.Encoded[kw])
+	}
+
+	// Longer payloads first, so that a payload which contains another one is stripped entirely.
+	sort.SliceStable(payloads, func(i, j int) bool { return len(payloads[i]) > len(payloads[j]) })
+
+	oldnew := []string{}
+	for _, p := range payloads {
+		if p != "" {
+			oldnew = append(oldnew, p, "")
+		}
+	}
+
+	return strings.NewReplacer(oldnew...).Replace(body)
+}
+
+// simhash calculates a 64-bit similarity hash of tokens. Similar token lists
+// have hashes which differ in only a few bits.
+func simhash(tokens []string) uint64 {
+	var weights [64]int
+	for _, t := range tokens {
+		h := fnv.New64a()
+		h.Write([]byte(t))
+		sum := h.Sum64()
+
+		for i := uint(0); i < 64; i++ {
+			if sum&(1<<i) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+
+	var hash uint64
+	for i := uint(0); i < 64; i++ {
+		if weights[i] > 0 {
+			hash |= 1 << i
+		}
+	}
+
+	return hash
+}
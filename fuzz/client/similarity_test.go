@@ -0,0 +1,66 @@
+package client
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsSimilar(t *testing.T) {
+	const h uint64 = 0xf0f0f0f0f0f0f0f0
+
+	tests := []struct {
+		a, b uint64
+		want bool
+	}{
+		{h, h, true},
+		{h, h ^ 0x1, true},
+		{h, h ^ 0x8000000000000101, true}, // maxSimilarityDistance bits differ
+		{h, h ^ 0x8000000000000111, false},
+		{h, ^h, false},
+	}
+
+	for _, tt := range tests {
+		if got := isSimilar(tt.a, tt.b); got != tt.want {
+			t.Errorf("isSimilar(%x, %x) = %t, want %t", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSimilarityIsNew(t *testing.T) {
+	page := "Not Found The requested URL %s was not found on this server. Additionally, a 404 Not Found error was encountered while trying to use an ErrorDocument to handle the request. Apache Server at example.com Port 80 please contact the administrator of this site for more information about the error and the time it occurred"
+	res := func(code int, payload, body string) *Result {
+		return &Result{
+			StatusCode: code,
+			Payload:    map[string]string{"FUZZ": payload},
+			body:       []byte(strings.Replace(body, "%s", payload, 1)),
+		}
+	}
+
+	tests := []struct {
+		res  *Result
+		want bool
+	}{
+		{res(404, "admin", page), true},
+		{res(404, "login", page), true},
+		{res(404, "a%20b", page), false}, // The echoed payload is stripped, maxShown is reached
+		{res(404, "index", strings.Replace(page, "Not", "request-id-12345", 1)), false}, // Near-duplicate
+		{res(404, "backup", "Welcome to the admin area. Log in to manage the users and the settings of the site"), true},
+		{res(500, "config", page), true}, // Another status code
+	}
+
+	s := newSimilarity(2)
+	for i, tt := range tests {
+		if got := s.isNew(tt.res); got != tt.want {
+			t.Errorf("%d: isNew(%s) = %t, want %t", i, tt.res.Payload["FUZZ"], got, tt.want)
+		}
+	}
+
+	if s.hidden() != 2 {
+		t.Errorf("hidden() = %d, want 2", s.hidden())
+	}
+
+	var none *similarity
+	if !none.isNew(res(404, "admin", page)) || none.hidden() != 0 {
+		t.Errorf("Without -fs a response is hidden")
+	}
+}
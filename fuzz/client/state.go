@@ -0,0 +1,119 @@
+package client
+
+// BaseState is the progress of a base URL. It is saved in a session file to resume a scan
+// without sending already completed requests again. Requests are identified by their
+// sequence number, which is given in the deterministic order of the producer. On resume
+// the wordlists are read from the start and the requests up to the watermark are skipped.
+type BaseState struct {
+	URL       string
+	Path      string
+	Depth     int
+	Finished  bool
+	Watermark uint64   // All requests with a lower sequence number are done
+	Done      []uint64 // Done requests with a sequence number above the watermark
+	Hits      []Hit    // Hits which are mutated after the main scan
+}
+
+// Resume continues a scan from the saved progress. Must be called before Run.
+func (f *Fuzzer) Resume(states []*BaseState) {
+	f.resumeStates = states
+}
+
+// State returns a snapshot of the progress of all base URLs.
+// Before the scan is started, this is the progress it resumes from.
+func (f *Fuzzer) State() []*BaseState {
+	f.mu.Lock()
+	rc := f.rec
+	f.mu.Unlock()
+
+	if rc == nil {
+		return f.resumeStates
+	}
+
+	rc.Lock()
+	bases := append([]*base{}, rc.all...)
+	rc.Unlock()
+
+	states := []*BaseState{}
+	for _, b := range bases {
+		states = append(states, b.state())
+	}
+
+	return states
+}
+
+// track assigns the next sequence number to a request and marks it as pending.
+// It reports false, if the request was already done in the resumed scan.
+func (b *base) track(r *request) bool {
+	b.Lock()
+	defer b.Unlock()
+
+	r.seq = b.produced
+	b.produced++
+
+	if b.resume != nil && (r.seq < b.resume.Watermark || b.resumeDone[r.seq]) {
+		return false
+	}
+
+	b.pending[r.seq] = r
+
+	return true
+}
+
+// done marks a request as completed. Untracked requests are ignored.
+func (b *base) done(r *request) {
+	b.Lock()
+	if b.pending[r.seq] == r {
+		delete(b.pending, r.seq)
+	}
+	b.Unlock()
+}
+
+// finish marks that all requests of the base were produced.
+func (b *base) finish() {
+	b.Lock()
+	b.complete = true
+	b.Unlock()
+}
+
+// state creates a snapshot of the progress. The watermark is the lowest pending request.
+func (b *base) state() *BaseState {
+	b.Lock()
+	defer b.Unlock()
+
+	s := &BaseState{
+		URL:       b.url,
+		Path:      b.path,
+		Depth:     b.depth,
+		Finished:  b.complete && len(b.pending) == 0,
+		Watermark: b.produced,
+		Done:      []uint64{},
+		Hits:      append([]Hit{}, b.hits...),
+	}
+
+	for seq := range b.pending {
+		if seq < s.Watermark {
+			s.Watermark = seq
+		}
+	}
+
+	for seq := s.Watermark; seq < b.produced; seq++ {
+		if _, isPending := b.pending[seq]; !isPending {
+			s.Done = append(s.Done, seq)
+		}
+	}
+
+	// Requests which were already done in the resumed scan, but not produced again yet.
+	if b.resume != nil {
+		if b.resume.Watermark > s.Watermark {
+			s.Watermark = b.resume.Watermark
+		}
+		for seq := range b.resumeDone {
+			if seq >= b.produced && seq >= s.Watermark {
+				s.Done = append(s.Done, seq)
+			}
+		}
+	}
+
+	return s
+}
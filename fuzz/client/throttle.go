@@ -0,0 +1,210 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// backoffBase is the wait time before the first retry. It doubles with every further retry.
+	backoffBase = 500 * time.Millisecond
+	// backoffMax caps the wait time of a single retry, also if the target asks for more with Retry-After.
+	backoffMax = 60 * time.Second
+	// throttleCooldown is the minimum time between two decreases of the concurrency level.
+	// Otherwise all requests in flight would decrease it at once.
+	throttleCooldown = time.Second
+	// rampUpSuccesses is the number of successful requests in a row, after which the
+	// concurrency level is increased again by one.
+	rampUpSuccesses = 20
+)
+
+// throttle is an adaptive controller for the concurrency level. If the target answers
+// with 429/503 or the connection fails, the number of concurrent requests is halved and
+// the target's Retry-After is honored. After a number of successful requests the
+// concurrency is slowly ramped up again. If a rate is set, it is scaled the same way.
+type throttle struct {
+	sync.Mutex
+	cond         *sync.Cond
+	enabled      bool
+	max          int
+	limit        int
+	active       int
+	successes    int
+	limiter      *rateLimiter // Scaled with the concurrency level
+	lastDecrease time.Time
+	pausedUntil  time.Time
+}
+
+func newThrottle(enabled bool, concurrency int, limiter *rateLimiter) *throttle {
+	t := &throttle{enabled: enabled, max: concurrency, limit: concurrency, limiter: limiter}
+	t.cond = sync.NewCond(t)
+
+	return t
+}
+
+// acquire blocks until a request is allowed by the current concurrency level.
+// It reports false, if the context was canceled in the meantime.
+func (t *throttle) acquire(ctx context.Context) bool {
+	if !t.enabled {
+		return ctx.Err() == nil
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	for {
+		if ctx.Err() != nil {
+			return false
+		}
+
+		if pause := time.Until(t.pausedUntil); pause > 0 {
+			t.Unlock()
+			sleep(ctx, pause)
+			t.Lock()
+			continue
+		}
+
+		if t.active < t.limit {
+			t.active++
+			return true
+		}
+
+		t.cond.Wait()
+	}
+}
+
+// wakeOnCancel wakes up all workers, which wait for a request slot in acquire, as soon as
+// the context is canceled. Otherwise they would wait for slots, which are never released.
+// It returns when done is closed.
+func (t *throttle) wakeOnCancel(ctx context.Context, done chan bool) {
+	select {
+	case <-ctx.Done():
+		t.Lock()
+		t.cond.Broadcast()
+		t.Unlock()
+	case <-done:
+	}
+}
+
+// release frees a request slot.
+func (t *throttle) release() {
+	if !t.enabled {
+		return
+	}
+
+	t.Lock()
+	t.active--
+	t.Unlock()
+	t.cond.Signal()
+}
+
+// observe adjusts the concurrency level to the outcome of a request. It reports
+// if the request was throttled by the target and how long the target wants us to wait.
+func (t *throttle) observe(res *Result, err error) (bool, time.Duration) {
+	if !t.enabled {
+		return false, 0
+	}
+
+	throttled := err == nil && (res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusServiceUnavailable)
+
+	var retryAfter time.Duration
+	if throttled {
+		retryAfter = parseRetryAfter(res.header.Get("Retry-After"))
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	if err == nil && !throttled {
+		t.successes++
+		if t.successes >= rampUpSuccesses && t.limit < t.max {
+			t.setLimit(t.limit + 1)
+		}
+		return false, 0
+	}
+
+	if retryAfter > 0 {
+		t.pausedUntil = time.Now().Add(retryAfter)
+	}
+
+	if time.Since(t.lastDecrease) > throttleCooldown {
+		t.lastDecrease = time.Now()
+		t.setLimit(t.limit / 2)
+	}
+
+	return throttled, retryAfter
+}
+
+// setLimit sets the concurrency level and scales the rate accordingly. Must be called with the lock held.
+func (t *throttle) setLimit(limit int) {
+	if limit < 1 {
+		limit = 1
+	}
+
+	t.limit = limit
+	t.successes = 0
+	t.cond.Broadcast()
+
+	t.limiter.SetScale(float64(t.limit) / float64(t.max))
+}
+
+// state describes the throttling for the progress output. It is empty while running at full speed.
+func (t *throttle) state() string {
+	if !t.enabled {
+		return ""
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	if pause := time.Until(t.pausedUntil); pause > 0 {
+		return fmt.Sprintf("paused %ds", int(pause.Seconds()+1))
+	}
+
+	if t.limit < t.max {
+		return fmt.Sprintf("throttled %d/%d", t.limit, t.max)
+	}
+
+	return ""
+}
+
+// backoff calculates the wait time before a retry: exponential with jitter,
+// unless the target asked for a specific time with Retry-After.
+func backoff(retry uint8, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+
+	d := backoffBase << (retry - 1)
+	if d > backoffMax || d <= 0 {
+		d = backoffMax
+	}
+
+	// Full jitter on the upper half, so that retries of all Go routines don't hit the target at once.
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// parseRetryAfter parses the Retry-After header, which is either in seconds or a HTTP date.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+
+	var d time.Duration
+	if secs, err := strconv.Atoi(v); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(v); err == nil {
+		d = time.Until(t)
+	}
+
+	if d > backoffMax {
+		d = backoffMax
+	}
+
+	return d
+}
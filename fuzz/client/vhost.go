@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/shellrausch/gofuzzy/fuzz/utils"
+)
+
+// baselineMetrics are the properties of a response, which are compared with the vhost baseline.
+var baselineMetrics = []struct {
+	name   string
+	value  func(*Result) int
+	isBody bool // A metric of the body
+}{
+	{"code", func(r *Result) int { return r.StatusCode }, false},
+	{"chars", func(r *Result) int { return r.ContentLength }, true},
+	{"words", func(r *Result) int { return r.NumWords }, true},
+	{"lines", func(r *Result) int { return r.NumLines }, true},
+	{"header", func(r *Result) int { return r.HeaderSize }, false},
+}
+
+// vhostBaseline is the response of the target to unknown virtual hosts, usually a default site.
+// It contains only the metrics, which are the same for all random hostnames. A metric which
+// changes with the hostname (e.g. because the host is reflected in the page) is not compared.
+// At least one metric of the body must be stable. The status code and the header size alone
+// are mostly the same for the real vhosts, which would be hidden then.
+type vhostBaseline struct {
+	values map[string]int // Metric name -> value
+}
+
+// fetchVHostBaseline requests random hostnames of different lengths, which certainly don't exist,
+// and learns the baseline from their responses. The baseline is listed in o.CalibratedFilters.
+func (f *Fuzzer) fetchVHostBaseline(ctx context.Context) {
+	o := f.o
+	root := newBase(strings.TrimSuffix(o.URL.String(), "/"), "", 0, nil)
+	header := utils.SplitHeaderFields(o.CustomHeader, o.HeaderFieldSep)
+
+	samples := []*Result{}
+	for i := 0; i < o.NumCalibrationRequests; i++ {
+		payload := map[string]string{}
+		for _, kw := range o.Wordlists.Keywords() {
+			payload[kw] = calibrationPayload(i)
+		}
+
+		res, err := f.invokeRequest(ctx, newRequest(o, root, header, payload, ""))
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			log.Printf("Unable to fetch the vhost baseline. All responses are shown: %s", err)
+			return
+		}
+		samples = append(samples, res)
+	}
+
+	f.baseline = newVHostBaseline(samples)
+	if len(f.baseline.values) == 0 {
+		log.Printf("The bodies of the responses to random hostnames differ in chars, words and lines, no vhost baseline is learned. All responses are shown")
+		return
+	}
+	o.CalibratedFilters = append(o.CalibratedFilters, f.baseline.String())
+}
+
+func newVHostBaseline(samples []*Result) *vhostBaseline {
+	b := &vhostBaseline{values: map[string]int{}}
+	hasBody := false
+
+	for _, m := range baselineMetrics {
+		v := m.value(samples[0])
+
+		isStable := true
+		for _, r := range samples[1:] {
+			if m.value(r) != v {
+				isStable = false
+			}
+		}
+
+		if isStable {
+			b.values[m.name] = v
+			hasBody = hasBody || m.isBody
+		}
+	}
+
+	if !hasBody {
+		b.values = map[string]int{}
+	}
+
+	return b
+}
+
+// differs reports if a response differs from the baseline in any of its metrics.
+// Without a baseline every response differs.
+func (b *vhostBaseline) differs(r *Result) bool {
+	if b == nil || len(b.values) == 0 {
+		return true
+	}
+
+	for _, m := range baselineMetrics {
+		if v, ok := b.values[m.name]; ok && m.value(r) != v {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b *vhostBaseline) String() string {
+	values := []string{}
+	for _, m := range baselineMetrics {
+		if v, ok := b.values[m.name]; ok {
+			values = append(values, fmt.Sprintf("%s %d", m.name, v))
+		}
+	}
+
+	return "vhost baseline (" + strings.Join(values, ", ") + ")"
+}
@@ -0,0 +1,47 @@
+package client
+
+import "testing"
+
+func TestNewVHostBaseline(t *testing.T) {
+	res := func(code, chars, words, lines, header int) *Result {
+		return &Result{StatusCode: code, ContentLength: chars, NumWords: words, NumLines: lines, HeaderSize: header}
+	}
+
+	tests := []struct {
+		samples []*Result
+		want    string
+		hit     *Result
+		miss    *Result
+	}{
+		{
+			[]*Result{res(200, 100, 10, 2, 80), res(200, 100, 10, 2, 80), res(200, 100, 10, 2, 80)},
+			"vhost baseline (code 200, chars 100, words 10, lines 2, header 80)",
+			res(200, 120, 10, 2, 80), res(200, 100, 10, 2, 80),
+		},
+		{
+			// The hostname is reflected in the page and in a header.
+			[]*Result{res(200, 101, 10, 2, 81), res(200, 105, 10, 2, 85), res(200, 110, 10, 2, 90)},
+			"vhost baseline (code 200, words 10, lines 2)",
+			res(200, 100, 12, 2, 80), res(200, 130, 10, 2, 95),
+		},
+		{
+			// Only the status code and the header size are stable, which would hide the real vhosts.
+			[]*Result{res(200, 101, 10, 2, 80), res(200, 105, 11, 3, 80), res(200, 110, 12, 4, 80)},
+			"vhost baseline ()",
+			res(200, 100, 10, 2, 80), nil,
+		},
+	}
+
+	for _, tt := range tests {
+		b := newVHostBaseline(tt.samples)
+		if b.String() != tt.want {
+			t.Errorf("newVHostBaseline() = %s, want %s", b, tt.want)
+		}
+		if !b.differs(tt.hit) {
+			t.Errorf("%s hides %+v", b, tt.hit)
+		}
+		if tt.miss != nil && b.differs(tt.miss) {
+			t.Errorf("%s shows %+v", b, tt.miss)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Resolver resolves hostnames against a list of DNS servers, which are used in turn.
+// Without servers the resolver of the system is used.
+type Resolver struct {
+	r       *net.Resolver
+	servers []string
+	next    uint32
+}
+
+// New creates a resolver for DNS servers in the format host[:port]. The port defaults to 53.
+func New(servers []string) *Resolver {
+	r := &Resolver{r: &net.Resolver{}}
+	for _, s := range servers {
+		r.servers = append(r.servers, ServerAddr(s))
+	}
+
+	if len(r.servers) > 0 {
+		// Only the Go resolver allows to dial a custom DNS server.
+		r.r.PreferGo = true
+		r.r.Dial = func(ctx context.Context, network, _ string) (net.Conn, error) {
+			server := r.servers[atomic.AddUint32(&r.next, 1)%uint32(len(r.servers))]
+			var d net.Dialer
+			return d.DialContext(ctx, network, server)
+		}
+	}
+
+	return r
+}
+
+// DialContext connects to an address like net.Dialer, but resolves the host with the DNS servers of the resolver.
+func (r *Resolver) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	d := net.Dialer{Resolver: r.r}
+	return d.DialContext(ctx, network, addr)
+}
+
+// ServerAddr adds the default port 53 to a DNS server, if it has none.
+func ServerAddr(s string) string {
+	if _, _, err := net.SplitHostPort(s); err == nil {
+		return s
+	}
+
+	return net.JoinHostPort(strings.Trim(s, "[]"), "53")
+}
+
+// Answer contains the records of a live host.
+type Answer struct {
+	CNAME string
+	A     []string
+	AAAA  []string
+}
+
+// Records returns all records in the format TYPE value. Example: A 10.0.0.1
+func (a *Answer) Records() []string {
+	records := []string{}
+	if a.CNAME != "" {
+		records = append(records, "CNAME "+a.CNAME)
+	}
+	for _, ip := range a.A {
+		records = append(records, "A "+ip)
+	}
+	for _, ip := range a.AAAA {
+		records = append(records, "AAAA "+ip)
+	}
+
+	return records
+}
+
+// Lookup resolves the A, AAAA and CNAME records of a host. It returns no answer and
+// no error, if the host doesn't exist. A host with a CNAME to a name, which doesn't
+// exist (a typical takeover candidate), has only the CNAME record.
+func (r *Resolver) Lookup(ctx context.Context, host string) (*Answer, error) {
+	// A fully qualified name is not expanded with the search domains of the system.
+	fqdn := strings.TrimSuffix(host, ".") + "."
+
+	a := &Answer{}
+
+	// A host without a CNAME record is its own canonical name.
+	if cname, err := r.r.LookupCNAME(ctx, fqdn); err == nil && !strings.EqualFold(cname, fqdn) {
+		a.CNAME = strings.TrimSuffix(cname, ".")
+	}
+
+	addrs, err := r.r.LookupIPAddr(ctx, fqdn)
+	if isNotFound(err) {
+		if a.CNAME != "" {
+			return a, nil
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, addr := range addrs {
+		if addr.IP.To4() != nil {
+			a.A = append(a.A, addr.IP.String())
+		} else {
+			a.AAAA = append(a.AAAA, addr.IP.String())
+		}
+	}
+	sort.Strings(a.A)
+	sort.Strings(a.AAAA)
+
+	return a, nil
+}
+
+func isNotFound(err error) bool {
+	dnsErr, ok := err.(*net.DNSError)
+	return ok && dnsErr.IsNotFound
+}
+
+// Wildcard contains the records, which a wildcard DNS entry returns for every host.
+type Wildcard struct {
+	addrs  map[string]bool
+	cnames map[string]bool
+}
+
+// DetectWildcard resolves hosts which certainly don't exist. If any of them resolves,
+// the domain has a wildcard entry. Without a wildcard entry no wildcard is returned.
+func (r *Resolver) DetectWildcard(ctx context.Context, hosts []string) (*Wildcard, error) {
+	w := &Wildcard{addrs: map[string]bool{}, cnames: map[string]bool{}}
+
+	for _, host := range hosts {
+		a, err := r.Lookup(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		if a == nil {
+			continue
+		}
+
+		if a.CNAME != "" {
+			w.cnames[a.CNAME] = true
+		}
+		for _, ip := range append(a.A, a.AAAA...) {
+			w.addrs[ip] = true
+		}
+	}
+
+	if len(w.addrs) == 0 && len(w.cnames) == 0 {
+		return nil, nil
+	}
+
+	return w, nil
+}
+
+// Matches reports if an answer is just the wildcard entry and not a host of its own.
+func (w *Wildcard) Matches(a *Answer) bool {
+	if w == nil {
+		return false
+	}
+
+	if a.CNAME != "" && w.cnames[a.CNAME] {
+		return true
+	}
+
+	ips := append(a.A, a.AAAA...)
+	for _, ip := range ips {
+		if !w.addrs[ip] {
+			return false
+		}
+	}
+
+	return len(ips) > 0
+}
+
+func (w *Wildcard) String() string {
+	records := []string{}
+	for cname := range w.cnames {
+		records = append(records, cname)
+	}
+	for ip := range w.addrs {
+		records = append(records, ip)
+	}
+	sort.Strings(records)
+
+	return fmt.Sprintf("wildcard DNS (%s)", strings.Join(records, ", "))
+}
@@ -0,0 +1,219 @@
+package dns
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	typeA     = 1
+	typeCNAME = 5
+	typeAAAA  = 28
+)
+
+// record is a record of the test zone. The data is an IP or the target of a CNAME.
+type record struct {
+	typ  uint16
+	data string
+}
+
+var testZone = map[string][]record{
+	"www.example.test":      {{typeA, "10.0.0.1"}},
+	"admin.example.test":    {{typeA, "10.0.0.2"}, {typeAAAA, "fd00::2"}},
+	"cdn.example.test":      {{typeCNAME, "www.example.test"}},
+	"takeover.example.test": {{typeCNAME, "gone.cloud.test"}},
+	"*.wild.test":           {{typeA, "10.9.9.9"}},
+	"real.wild.test":        {{typeA, "10.1.1.1"}},
+}
+
+// serve starts a DNS server on a random local UDP port, which answers from the test zone.
+// It returns a resolver which uses the server and a function to stop the server.
+func serve(t *testing.T) (*Resolver, func()) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if resp := answer(buf[:n]); resp != nil {
+				conn.WriteTo(resp, addr)
+			}
+		}
+	}()
+
+	return New([]string{conn.LocalAddr().String()}), func() { conn.Close() }
+}
+
+// answer creates the response to a query with a single question.
+func answer(query []byte) []byte {
+	if len(query) < 12 {
+		return nil
+	}
+
+	labels := []string{}
+	i := 12
+	for i < len(query) && query[i] != 0 {
+		l := int(query[i])
+		if i+1+l > len(query) {
+			return nil
+		}
+		labels = append(labels, string(query[i+1:i+1+l]))
+		i += l + 1
+	}
+	if i+5 > len(query) {
+		return nil
+	}
+	qtype := binary.BigEndian.Uint16(query[i+1:])
+	questionEnd := i + 5
+
+	records, found := resolve(strings.ToLower(strings.Join(labels, ".")), qtype)
+
+	flags := uint16(0x8180) // Response, recursion desired and available
+	if !found {
+		flags |= 3 // NXDOMAIN
+	}
+
+	resp := make([]byte, 12)
+	copy(resp, query[:2])
+	binary.BigEndian.PutUint16(resp[2:], flags)
+	binary.BigEndian.PutUint16(resp[4:], 1)
+	binary.BigEndian.PutUint16(resp[6:], uint16(len(records)))
+	resp = append(resp, query[12:questionEnd]...)
+	for _, r := range records {
+		resp = append(resp, r...)
+	}
+
+	return resp
+}
+
+// resolve returns the encoded records of a name and follows CNAMEs like a recursive resolver.
+// It reports false, if the name (or the target of a CNAME) doesn't exist.
+func resolve(name string, qtype uint16) ([][]byte, bool) {
+	records, ok := testZone[name]
+	if !ok && strings.Contains(name, ".") {
+		records, ok = testZone["*"+name[strings.Index(name, "."):]]
+	}
+	if !ok {
+		return nil, false
+	}
+
+	encoded := [][]byte{}
+	for _, r := range records {
+		switch {
+		case r.typ == typeCNAME:
+			encoded = append(encoded, encodeRecord(name, r.typ, encodeName(r.data)))
+			if qtype == typeCNAME {
+				return encoded, true
+			}
+			target, found := resolve(r.data, qtype)
+			return append(encoded, target...), found
+		case r.typ == qtype && r.typ == typeA:
+			encoded = append(encoded, encodeRecord(name, r.typ, net.ParseIP(r.data).To4()))
+		case r.typ == qtype && r.typ == typeAAAA:
+			encoded = append(encoded, encodeRecord(name, r.typ, net.ParseIP(r.data).To16()))
+		}
+	}
+
+	return encoded, true
+}
+
+func encodeRecord(name string, typ uint16, data []byte) []byte {
+	b := encodeName(name)
+	fields := make([]byte, 10)
+	binary.BigEndian.PutUint16(fields, typ)
+	binary.BigEndian.PutUint16(fields[2:], 1)  // Class IN
+	binary.BigEndian.PutUint32(fields[4:], 60) // TTL
+	binary.BigEndian.PutUint16(fields[8:], uint16(len(data)))
+
+	return append(append(b, fields...), data...)
+}
+
+func encodeName(name string) []byte {
+	b := []byte{}
+	for _, l := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		b = append(b, byte(len(l)))
+		b = append(b, l...)
+	}
+
+	return append(b, 0)
+}
+
+func TestLookup(t *testing.T) {
+	r, stop := serve(t)
+	defer stop()
+
+	tests := []struct {
+		host    string
+		records []string
+	}{
+		{"www.example.test", []string{"A 10.0.0.1"}},
+		{"admin.example.test", []string{"A 10.0.0.2", "AAAA fd00::2"}},
+		{"cdn.example.test", []string{"CNAME www.example.test", "A 10.0.0.1"}},
+		{"takeover.example.test", []string{"CNAME gone.cloud.test"}},
+	}
+
+	for _, tt := range tests {
+		a, err := r.Lookup(context.Background(), tt.host)
+		if err != nil {
+			t.Errorf("Lookup(%s) failed: %s", tt.host, err)
+			continue
+		}
+		if a == nil {
+			t.Errorf("Lookup(%s) = no answer, want %v", tt.host, tt.records)
+			continue
+		}
+		if !reflect.DeepEqual(a.Records(), tt.records) {
+			t.Errorf("Lookup(%s) = %v, want %v", tt.host, a.Records(), tt.records)
+		}
+	}
+}
+
+func TestLookupNotFound(t *testing.T) {
+	r, stop := serve(t)
+	defer stop()
+
+	a, err := r.Lookup(context.Background(), "missing.example.test")
+	if err != nil || a != nil {
+		t.Errorf("Lookup(missing.example.test) = %v, %v, want no answer and no error", a, err)
+	}
+}
+
+func TestDetectWildcard(t *testing.T) {
+	r, stop := serve(t)
+	defer stop()
+	ctx := context.Background()
+
+	w, err := r.DetectWildcard(ctx, []string{"qwertzui.example.test", "asdfghjkasdfghjk.example.test"})
+	if err != nil || w != nil {
+		t.Errorf("DetectWildcard(example.test) = %v, %v, want no wildcard", w, err)
+	}
+
+	w, err = r.DetectWildcard(ctx, []string{"qwertzui.wild.test", "asdfghjkasdfghjk.wild.test"})
+	if err != nil || w == nil {
+		t.Fatalf("DetectWildcard(wild.test) = %v, %v, want a wildcard", w, err)
+	}
+	if w.String() != "wildcard DNS (10.9.9.9)" {
+		t.Errorf("Wildcard = %s, want wildcard DNS (10.9.9.9)", w)
+	}
+
+	for host, want := range map[string]bool{"www.wild.test": true, "real.wild.test": false} {
+		a, err := r.Lookup(ctx, host)
+		if err != nil || a == nil {
+			t.Errorf("Lookup(%s) = %v, %v, want an answer", host, a, err)
+			continue
+		}
+		if w.Matches(a) != want {
+			t.Errorf("Matches(%s) = %t, want %t", host, !want, want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package encoder
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+)
+
+// Encoder transforms a payload before it is placed in the request.
+type Encoder func(string) string
+
+// registry contains all available encoders by name.
+var registry = map[string]Encoder{
+	"urlencode":       urlEncode,
+	"doubleurlencode": func(s string) string { return urlEncode(urlEncode(s)) },
+	"base64":          func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+	"base64url":       func(s string) string { return base64.URLEncoding.EncodeToString([]byte(s)) },
+	"hex":             func(s string) string { return hex.EncodeToString([]byte(s)) },
+	"html":            html.EscapeString,
+	"htmlentities":    htmlEntities,
+	"unicode":         unicodeEscape,
+	"md5":             func(s string) string { return fmt.Sprintf("%x", md5.Sum([]byte(s))) },
+	"sha1":            func(s string) string { return fmt.Sprintf("%x", sha1.Sum([]byte(s))) },
+	"sha256":          func(s string) string { return fmt.Sprintf("%x", sha256.Sum256([]byte(s))) },
+	"upper":           strings.ToUpper,
+	"lower":           strings.ToLower,
+}
+
+// Names returns the names of all available encoders, sorted alphabetically.
+func Names() []string {
+	names := []string{}
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Chain is a list of encoders which are applied left to right.
+type Chain []Encoder
+
+// ParseChain parses encoder names separated by comma. Example: urlencode,base64
+func ParseChain(raw string) (Chain, error) {
+	chain := Chain{}
+	for _, name := range strings.Split(raw, ",") {
+		enc, ok := registry[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("Unknown encoder '%s'. Available encoders: %s", name, strings.Join(Names(), ", "))
+		}
+		chain = append(chain, enc)
+	}
+
+	return chain, nil
+}
+
+// Encode applies all encoders of the chain to the payload.
+func (c Chain) Encode(s string) string {
+	for _, enc := range c {
+		s = enc(s)
+	}
+
+	return s
+}
+
+// urlEncode percent-encodes every byte except the unreserved characters of RFC 3986.
+// Unlike url.QueryEscape a space is encoded as %20, so the result fits everywhere in a URL.
+func urlEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isUnreserved(c) {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
+
+func isUnreserved(c byte) bool {
+	return isAlphanumeric(c) || c == '-' || c == '_' || c == '.' || c == '~'
+}
+
+func isAlphanumeric(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// htmlEntities encodes every character, which is not alphanumeric, as a numeric HTML entity.
+func htmlEntities(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r < 128 && isAlphanumeric(byte(r)) {
+			b.WriteRune(r)
+		} else {
+			fmt.Fprintf(&b, "&#%d;", r)
+		}
+	}
+
+	return b.String()
+}
+
+// unicodeEscape escapes every character as \uXXXX. Characters outside the
+// Basic Multilingual Plane are escaped as UTF-16 surrogate pairs, like in JavaScript and JSON.
+func unicodeEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r > 0xFFFF {
+			r -= 0x10000
+			fmt.Fprintf(&b, "\\u%04x\\u%04x", 0xD800+(r>>10), 0xDC00+(r&0x3FF))
+		} else {
+			fmt.Fprintf(&b, "\\u%04x", r)
+		}
+	}
+
+	return b.String()
+}
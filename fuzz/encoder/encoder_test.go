@@ -0,0 +1,77 @@
+package encoder
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncoders(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"urlencode", "a b/ü-_.~", "a%20b%2F%C3%BC-_.~"},
+		{"doubleurlencode", "a b", "a%2520b"},
+		{"base64", "??>", "Pz8+"},
+		{"base64url", "??>", "Pz8-"},
+		{"hex", "ab", "6162"},
+		{"html", `<a href="x">&'`, "&lt;a href=&#34;x&#34;&gt;&amp;&#39;"},
+		{"htmlentities", "a<b ü", "a&#60;b&#32;&#252;"},
+		{"unicode", "a😀", `\u0061\ud83d\ude00`},
+		{"md5", "admin", "21232f297a57a5a743894a0e4a801fc3"},
+		{"sha1", "admin", "d033e22ae348aeb5660fc2140aec35850c4da997"},
+		{"sha256", "admin", "8c6976e5b5410415bde908bd4dee15dfb167a9c873fc4bb8a81f6f2ab448a918"},
+		{"upper", "Admin", "ADMIN"},
+		{"lower", "Admin", "admin"},
+	}
+
+	tested := map[string]bool{}
+	for _, tt := range tests {
+		tested[tt.name] = true
+
+		chain, err := ParseChain(tt.name)
+		if err != nil {
+			t.Errorf("ParseChain(%s) failed: %s", tt.name, err)
+			continue
+		}
+		if got := chain.Encode(tt.in); got != tt.want {
+			t.Errorf("%s(%s) = %s, want %s", tt.name, tt.in, got, tt.want)
+		}
+	}
+
+	for _, name := range Names() {
+		if !tested[name] {
+			t.Errorf("The encoder %s is not tested", name)
+		}
+	}
+}
+
+func TestParseChain(t *testing.T) {
+	tests := []struct {
+		raw  string
+		in   string
+		want string
+		err  string
+	}{
+		{"urlencode,base64", "a b", "YSUyMGI=", ""},
+		{"base64,urlencode", "a b", "YSBi", ""},
+		{"md5,upper", "admin", "21232F297A57A5A743894A0E4A801FC3", ""},
+		{" URLencode , Hex ", "a b", "6125323062", ""},
+		{"", "", "", "Unknown encoder ''"},
+		{"urlencode,", "", "", "Unknown encoder ''"},
+		{"rot13", "", "", "Unknown encoder 'rot13'. Available encoders: base64, base64url"},
+	}
+
+	for _, tt := range tests {
+		chain, err := ParseChain(tt.raw)
+		switch {
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("ParseChain(%s) = %v, want an error with '%s'", tt.raw, err, tt.err)
+		case tt.err == "" && err != nil:
+			t.Errorf("ParseChain(%s) failed: %s", tt.raw, err)
+		case tt.err == "" && chain.Encode(tt.in) != tt.want:
+			t.Errorf("ParseChain(%s).Encode(%s) = %s, want %s", tt.raw, tt.in, chain.Encode(tt.in), tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxCount is the largest number of payloads a generator can produce.
+const maxCount = ^uint(0)
+
+// charset generates all strings of a charset with a length in a range, shortest first.
+// Example: charset:a-z0-9:len=1-4
+type charset struct {
+	spec   string
+	chars  []rune
+	minLen int
+	maxLen int
+	count  uint
+}
+
+func newCharset(spec string, options map[string]string) (*charset, error) {
+	if err := checkOptions(options, "len"); err != nil {
+		return nil, err
+	}
+
+	c := &charset{spec: "charset:" + spec, chars: parseCharset(spec), minLen: 1, maxLen: 1}
+
+	if v, ok := options["len"]; ok {
+		bounds := strings.SplitN(v, "-", 2)
+		if len(bounds) == 1 {
+			bounds = append(bounds, bounds[0])
+		}
+
+		var err1, err2 error
+		c.minLen, err1 = strconv.Atoi(bounds[0])
+		c.maxLen, err2 = strconv.Atoi(bounds[1])
+		if err1 != nil || err2 != nil || c.minLen < 1 || c.minLen > c.maxLen {
+			return nil, fmt.Errorf("The length must be a number or a range, e.g. len=4 or len=1-4")
+		}
+	}
+
+	// The count is computed up front, which also rejects charsets which could never be completed.
+	n := uint(len(c.chars))
+	for l := c.minLen; l <= c.maxLen; l++ {
+		combinations := uint(1)
+		for i := 0; i < l; i++ {
+			if combinations > maxCount/n {
+				return nil, fmt.Errorf("Too many combinations. Reduce the charset or the length")
+			}
+			combinations *= n
+		}
+		if c.count > maxCount-combinations {
+			return nil, fmt.Errorf("Too many combinations. Reduce the charset or the length")
+		}
+		c.count += combinations
+	}
+
+	return c, nil
+}
+
+// parseCharset expands ranges like a-z. A dash at the beginning or the end is taken literally.
+func parseCharset(spec string) []rune {
+	seen := map[rune]bool{}
+	chars := []rune{}
+	add := func(r rune) {
+		if !seen[r] {
+			seen[r] = true
+			chars = append(chars, r)
+		}
+	}
+
+	runes := []rune(spec)
+	for i := 0; i < len(runes); i++ {
+		if i+2 < len(runes) && runes[i+1] == '-' && runes[i] <= runes[i+2] {
+			for r := runes[i]; r <= runes[i+2]; r++ {
+				add(r)
+			}
+			i += 2
+			continue
+		}
+		add(runes[i])
+	}
+
+	return chars
+}
+
+func (c *charset) Count() uint    { return c.count }
+func (c *charset) String() string { return c.spec }
+
+func (c *charset) Iterate() Iterator {
+	n := uint(len(c.chars))
+
+	return &iterator{count: c.count, at: func(i uint) string {
+		// Find the length of the i-th string. All shorter strings come first.
+		l := c.minLen
+		for {
+			combinations := uint(1)
+			for j := 0; j < l; j++ {
+				combinations *= n
+			}
+			if i < combinations {
+				break
+			}
+			i -= combinations
+			l++
+		}
+
+		// The index within the length is a number in base n, the most significant char first.
+		s := make([]rune, l)
+		for j := l - 1; j >= 0; j-- {
+			s[j] = c.chars[i%n]
+			i /= n
+		}
+
+		return string(s)
+	}}
+}
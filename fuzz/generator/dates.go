@@ -0,0 +1,65 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayout is the layout of the date range in the spec and the default output format.
+const dateLayout = "2006-01-02"
+
+// dates generates all days of a date range. The format is a Go time layout.
+// Example: dates:2020-01-01..2024-12-31:fmt=20060102
+type dates struct {
+	spec   string
+	from   time.Time
+	step   int
+	format string
+	count  uint
+}
+
+func newDates(spec string, options map[string]string) (*dates, error) {
+	if err := checkOptions(options, "fmt", "step"); err != nil {
+		return nil, err
+	}
+
+	bounds := strings.SplitN(spec, "..", 2)
+	if len(bounds) != 2 {
+		return nil, fmt.Errorf("The date range must be in the format %s..%s", dateLayout, dateLayout)
+	}
+
+	from, err1 := time.Parse(dateLayout, bounds[0])
+	to, err2 := time.Parse(dateLayout, bounds[1])
+	if err1 != nil || err2 != nil || from.After(to) {
+		return nil, fmt.Errorf("The date range must be in the format %s..%s with from <= to", dateLayout, dateLayout)
+	}
+
+	d := &dates{spec: "dates:" + spec, from: from, step: 1, format: dateLayout}
+
+	if v, ok := options["fmt"]; ok && v != "" {
+		d.format = v
+	}
+
+	if v, ok := options["step"]; ok {
+		var err error
+		if d.step, err = strconv.Atoi(v); err != nil || d.step < 1 {
+			return nil, fmt.Errorf("The step must be a number of days >=1")
+		}
+	}
+
+	days := int(to.Sub(from).Hours() / 24)
+	d.count = uint(days/d.step) + 1
+
+	return d, nil
+}
+
+func (d *dates) Count() uint    { return d.count }
+func (d *dates) String() string { return d.spec }
+
+func (d *dates) Iterate() Iterator {
+	return &iterator{count: d.count, at: func(i uint) string {
+		return d.from.AddDate(0, 0, int(i)*d.step).Format(d.format)
+	}}
+}
@@ -0,0 +1,108 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Generator produces payloads on the fly, so that no huge wordlist must be created beforehand.
+type Generator interface {
+	// Count returns the number of payloads, without generating them.
+	Count() uint
+	// Iterate starts a new iteration over all payloads.
+	Iterate() Iterator
+	// String returns the spec of the generator as passed on the command line.
+	String() string
+}
+
+// Iterator returns the payloads one after the other.
+type Iterator interface {
+	// Next returns the next payload. It reports false, if there are no more payloads.
+	Next() (string, bool)
+	Close() error
+}
+
+// Types lists all generator types in the format type:spec[:option=value...].
+var Types = []string{
+	"range:1-1000[:step=1][:pad=0]",
+	"charset:a-z0-9[:len=1]",
+	"dates:2020-01-01..2020-12-31[:fmt=2006-01-02][:step=1]",
+	"list:admin,root,test",
+}
+
+// Parse creates a generator from a spec in the format type:spec[:option=value...].
+// Example: range:1-100000:step=1:pad=6
+func Parse(raw string) (Generator, error) {
+	parts := strings.Split(raw, ":")
+	if len(parts) < 2 || parts[1] == "" {
+		return nil, fmt.Errorf("Malformed generator '%s'. Use the format type:spec[:option=value...], e.g. range:1-100", raw)
+	}
+
+	options := map[string]string{}
+	for _, o := range parts[2:] {
+		kv := strings.SplitN(o, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("Malformed option '%s' of generator '%s'. Use option=value", o, raw)
+		}
+		options[kv[0]] = kv[1]
+	}
+
+	var g Generator
+	var err error
+	switch parts[0] {
+	case "range":
+		g, err = newRange(parts[1], options)
+	case "charset":
+		g, err = newCharset(parts[1], options)
+	case "dates":
+		g, err = newDates(parts[1], options)
+	case "list":
+		g, err = newList(parts[1], options)
+	default:
+		return nil, fmt.Errorf("Unknown generator type '%s'. Supported: %s", parts[0], strings.Join(Types, ", "))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid generator '%s'. %s", raw, err)
+	}
+
+	return g, nil
+}
+
+// checkOptions returns an error, if an option is given, which is not supported by the generator.
+func checkOptions(options map[string]string, supported ...string) error {
+	for o := range options {
+		isSupported := false
+		for _, s := range supported {
+			if o == s {
+				isSupported = true
+			}
+		}
+
+		if !isSupported {
+			return fmt.Errorf("Unknown option '%s'", o)
+		}
+	}
+
+	return nil
+}
+
+// iterator turns a function, which computes the i-th payload, into an Iterator.
+type iterator struct {
+	i     uint
+	count uint
+	at    func(uint) string
+}
+
+func (it *iterator) Next() (string, bool) {
+	if it.i >= it.count {
+		return "", false
+	}
+
+	p := it.at(it.i)
+	it.i++
+
+	return p, true
+}
+
+func (it *iterator) Close() error { return nil }
@@ -0,0 +1,94 @@
+package generator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want []string
+		err  string
+	}{
+		{"range:1-5", []string{"1", "2", "3", "4", "5"}, ""},
+		{"range:5-5", []string{"5"}, ""},
+		{"range:1-10:step=3", []string{"1", "4", "7", "10"}, ""},
+		{"range:1-9:step=3", []string{"1", "4", "7"}, ""},
+		{"range:8-11:pad=3", []string{"008", "009", "010", "011"}, ""},
+		{"range:5-1", nil, "from <= to"},
+		{"range:a-b", nil, "from <= to"},
+		{"range:1", nil, "format from-to"},
+		{"range:1-5:step=0", nil, "step must be a number >=1"},
+		{"range:1-5:pad=-1", nil, "padding must be a number >=0"},
+		{"range:1-5:len=2", nil, "Unknown option 'len'"},
+
+		{"charset:a-c", []string{"a", "b", "c"}, ""},
+		{"charset:ab:len=1-2", []string{"a", "b", "aa", "ab", "ba", "bb"}, ""},
+		{"charset:ab:len=2", []string{"aa", "ab", "ba", "bb"}, ""},
+		{"charset:a-ca", []string{"a", "b", "c"}, ""}, // Duplicates are removed
+		{"charset:-a-", []string{"-", "a"}, ""},       // A dash at the beginning or the end is literal
+		{"charset:ab:len=0", nil, "The length must be"},
+		{"charset:ab:len=3-1", nil, "The length must be"},
+		{"charset:ab:len=x", nil, "The length must be"},
+		{"charset:a-z0-9:len=1-20", nil, "Too many combinations"},
+
+		{"dates:2020-02-27..2020-03-01", []string{"2020-02-27", "2020-02-28", "2020-02-29", "2020-03-01"}, ""},
+		{"dates:2020-02-27..2020-03-01:fmt=20060102:step=2", []string{"20200227", "20200229"}, ""},
+		{"dates:2020-12-31..2021-01-01:fmt=Jan 2", []string{"Dec 31", "Jan 1"}, ""},
+		{"dates:2020-03-01..2020-02-01", nil, "from <= to"},
+		{"dates:2020-13-01..2020-12-31", nil, "from <= to"},
+		{"dates:2020-01-01", nil, "in the format"},
+		{"dates:2020-01-01..2020-01-02:step=0", nil, "step must be a number of days >=1"},
+
+		{"list:admin,root,test", []string{"admin", "root", "test"}, ""},
+		{"list:admin", []string{"admin"}, ""},
+		{"list:a,,b", []string{"a", "", "b"}, ""},
+		{"list:admin:step=1", nil, "Unknown option 'step'"},
+
+		{"range", nil, "Malformed generator"},
+		{"range:", nil, "Malformed generator"},
+		{"range:1-5:step", nil, "Malformed option 'step'"},
+		{"words:a-z", nil, "Unknown generator type 'words'"},
+	}
+
+	for _, tt := range tests {
+		g, err := Parse(tt.raw)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Parse(%s) = %v, want an error with '%s'", tt.raw, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%s) failed: %s", tt.raw, err)
+			continue
+		}
+
+		if !strings.HasPrefix(tt.raw, g.String()) {
+			t.Errorf("Parse(%s).String() = %s", tt.raw, g.String())
+		}
+		if g.Count() != uint(len(tt.want)) {
+			t.Errorf("Parse(%s).Count() = %d, want %d", tt.raw, g.Count(), len(tt.want))
+		}
+
+		// Every iteration starts from the beginning.
+		for i := 0; i < 2; i++ {
+			if got := payloads(g.Iterate()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%s) generates %q, want %q", tt.raw, got, tt.want)
+			}
+		}
+	}
+}
+
+func payloads(it Iterator) []string {
+	defer it.Close()
+
+	p := []string{}
+	for s, ok := it.Next(); ok; s, ok = it.Next() {
+		p = append(p, s)
+	}
+
+	return p
+}
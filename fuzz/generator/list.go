@@ -0,0 +1,26 @@
+package generator
+
+import "strings"
+
+// list generates the values of a list, separated by comma. Example: list:admin,root,test
+type list struct {
+	spec   string
+	values []string
+}
+
+func newList(spec string, options map[string]string) (*list, error) {
+	if err := checkOptions(options); err != nil {
+		return nil, err
+	}
+
+	return &list{spec: "list:" + spec, values: strings.Split(spec, ",")}, nil
+}
+
+func (l *list) Count() uint    { return uint(len(l.values)) }
+func (l *list) String() string { return l.spec }
+
+func (l *list) Iterate() Iterator {
+	return &iterator{count: uint(len(l.values)), at: func(i uint) string {
+		return l.values[i]
+	}}
+}
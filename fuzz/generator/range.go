@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// numRange generates the numbers of a range, e.g. for IDs. Example: range:1-100000:step=1:pad=6
+type numRange struct {
+	spec  string
+	from  int64
+	step  int64
+	pad   int
+	count uint
+}
+
+func newRange(spec string, options map[string]string) (*numRange, error) {
+	if err := checkOptions(options, "step", "pad"); err != nil {
+		return nil, err
+	}
+
+	bounds := strings.SplitN(spec, "-", 2)
+	if len(bounds) != 2 {
+		return nil, fmt.Errorf("The range must be in the format from-to, e.g. 1-100")
+	}
+
+	from, err1 := strconv.ParseInt(bounds[0], 10, 64)
+	to, err2 := strconv.ParseInt(bounds[1], 10, 64)
+	if err1 != nil || err2 != nil || from > to {
+		return nil, fmt.Errorf("The range must be in the format from-to with from <= to, e.g. 1-100")
+	}
+
+	r := &numRange{spec: "range:" + spec, from: from, step: 1}
+
+	if v, ok := options["step"]; ok {
+		if r.step, err1 = strconv.ParseInt(v, 10, 64); err1 != nil || r.step < 1 {
+			return nil, fmt.Errorf("The step must be a number >=1")
+		}
+	}
+
+	if v, ok := options["pad"]; ok {
+		if r.pad, err1 = strconv.Atoi(v); err1 != nil || r.pad < 0 {
+			return nil, fmt.Errorf("The padding must be a number >=0")
+		}
+	}
+
+	r.count = uint((to-from)/r.step) + 1
+
+	return r, nil
+}
+
+func (r *numRange) Count() uint    { return r.count }
+func (r *numRange) String() string { return r.spec }
+
+func (r *numRange) Iterate() Iterator {
+	return &iterator{count: r.count, at: func(i uint) string {
+		return fmt.Sprintf("%0*d", r.pad, r.from+int64(i)*r.step)
+	}}
+}
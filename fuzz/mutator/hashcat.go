@@ -0,0 +1,244 @@
+package mutator
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// hashcatRule is a rule in the hashcat rule syntax. It consists of functions which
+// are applied left to right and creates a single variant. Example: ^. $. $s $w $p
+// See https://hashcat.net/wiki/doku.php?id=rule_based_attack
+type hashcatRule struct {
+	funcs []func([]rune) []rune
+}
+
+func (h hashcatRule) Apply(word string) []string {
+	w := []rune(word)
+	for _, f := range h.funcs {
+		w = f(w)
+	}
+
+	return []string{string(w)}
+}
+
+func (hashcatRule) NumVariants() int { return 1 }
+
+// parseHashcatRule parses the supported hashcat functions. Spaces between functions are ignored.
+func parseHashcatRule(raw string) (hashcatRule, error) {
+	rule := hashcatRule{}
+	r := []rune(raw)
+
+	// arg returns the n-th argument of the function at i.
+	arg := func(i, n int) (rune, error) {
+		if i+n >= len(r) {
+			return 0, fmt.Errorf("Missing argument of rule function '%c' in '%s'", r[i], raw)
+		}
+		return r[i+n], nil
+	}
+
+	for i := 0; i < len(r); i++ {
+		var f func([]rune) []rune
+		numArgs := 0
+
+		switch r[i] {
+		case ' ', ':':
+			continue
+		case 'l':
+			f = func(w []rune) []rune { return []rune(strings.ToLower(string(w))) }
+		case 'u':
+			f = func(w []rune) []rune { return []rune(strings.ToUpper(string(w))) }
+		case 'c':
+			f = func(w []rune) []rune { return []rune(capitalize(string(w))) }
+		case 'C':
+			f = func(w []rune) []rune {
+				w = []rune(strings.ToUpper(string(w)))
+				if len(w) > 0 {
+					w[0] = unicode.ToLower(w[0])
+				}
+				return w
+			}
+		case 't':
+			f = func(w []rune) []rune {
+				for j := range w {
+					w[j] = toggle(w[j])
+				}
+				return w
+			}
+		case 'r':
+			f = func(w []rune) []rune {
+				rev := make([]rune, len(w))
+				for j := range w {
+					rev[len(w)-1-j] = w[j]
+				}
+				return rev
+			}
+		case 'd':
+			f = func(w []rune) []rune { return append(w, w...) }
+		case '[':
+			f = func(w []rune) []rune {
+				if len(w) > 0 {
+					return w[1:]
+				}
+				return w
+			}
+		case ']':
+			f = func(w []rune) []rune {
+				if len(w) > 0 {
+					return w[:len(w)-1]
+				}
+				return w
+			}
+		case '{':
+			f = func(w []rune) []rune {
+				if len(w) > 0 {
+					return append(w[1:], w[0])
+				}
+				return w
+			}
+		case '}':
+			f = func(w []rune) []rune {
+				if len(w) > 0 {
+					return append([]rune{w[len(w)-1]}, w[:len(w)-1]...)
+				}
+				return w
+			}
+		case '$', '^', '@', 'T', 'D', '\'':
+			numArgs = 1
+			a, err := arg(i, 1)
+			if err != nil {
+				return rule, err
+			}
+			f, err = hashcatFunc1(r[i], a)
+			if err != nil {
+				return rule, err
+			}
+		case 's', 'i', 'o':
+			numArgs = 2
+			a, err := arg(i, 1)
+			if err != nil {
+				return rule, err
+			}
+			b, err := arg(i, 2)
+			if err != nil {
+				return rule, err
+			}
+			f, err = hashcatFunc2(r[i], a, b)
+			if err != nil {
+				return rule, err
+			}
+		default:
+			return rule, fmt.Errorf("Unsupported rule function '%c' in '%s'", r[i], raw)
+		}
+
+		rule.funcs = append(rule.funcs, f)
+		i += numArgs
+	}
+
+	return rule, nil
+}
+
+// hashcatFunc1 creates a function with a single argument, which is a char or a position.
+func hashcatFunc1(name, a rune) (func([]rune) []rune, error) {
+	switch name {
+	case '$':
+		return func(w []rune) []rune { return append(w, a) }, nil
+	case '^':
+		return func(w []rune) []rune { return append([]rune{a}, w...) }, nil
+	case '@':
+		return func(w []rune) []rune {
+			purged := []rune{}
+			for _, c := range w {
+				if c != a {
+					purged = append(purged, c)
+				}
+			}
+			return purged
+		}, nil
+	}
+
+	n, err := position(a)
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case 'T':
+		return func(w []rune) []rune {
+			if n < len(w) {
+				w[n] = toggle(w[n])
+			}
+			return w
+		}, nil
+	case 'D':
+		return func(w []rune) []rune {
+			if n < len(w) {
+				return append(w[:n:n], w[n+1:]...)
+			}
+			return w
+		}, nil
+	default: // Truncate
+		return func(w []rune) []rune {
+			if n < len(w) {
+				return w[:n]
+			}
+			return w
+		}, nil
+	}
+}
+
+// hashcatFunc2 creates a function with two arguments.
+func hashcatFunc2(name, a, b rune) (func([]rune) []rune, error) {
+	if name == 's' {
+		return func(w []rune) []rune {
+			for j := range w {
+				if w[j] == a {
+					w[j] = b
+				}
+			}
+			return w
+		}, nil
+	}
+
+	n, err := position(a)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == 'i' {
+		return func(w []rune) []rune {
+			if n <= len(w) {
+				return append(w[:n:n], append([]rune{b}, w[n:]...)...)
+			}
+			return w
+		}, nil
+	}
+
+	// Overwrite
+	return func(w []rune) []rune {
+		if n < len(w) {
+			w[n] = b
+		}
+		return w
+	}, nil
+}
+
+// position converts a hashcat position (0-9, A-Z) to a number.
+func position(p rune) (int, error) {
+	switch {
+	case p >= '0' && p <= '9':
+		return int(p - '0'), nil
+	case p >= 'A' && p <= 'Z':
+		return int(p-'A') + 10, nil
+	}
+
+	return 0, fmt.Errorf("Invalid position '%c' in rule. Use 0-9 or A-Z", p)
+}
+
+func toggle(c rune) rune {
+	if unicode.IsUpper(c) {
+		return unicode.ToLower(c)
+	}
+
+	return unicode.ToUpper(c)
+}
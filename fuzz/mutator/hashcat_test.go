@@ -0,0 +1,69 @@
+package mutator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseHashcatRule(t *testing.T) {
+	tests := []struct {
+		rule string
+		word string
+		want string
+		err  string
+	}{
+		{"l", "PassWord", "password", ""},
+		{"u", "PassWord", "PASSWORD", ""},
+		{"c", "passWORD", "Password", ""},
+		{"C", "password", "pASSWORD", ""},
+		{"t", "PassWord", "pASSwORD", ""},
+		{"r", "abc", "cba", ""},
+		{"d", "ab", "abab", ""},
+		{"[", "abc", "bc", ""},
+		{"[", "", "", ""},
+		{"]", "abc", "ab", ""},
+		{"{", "abc", "bca", ""},
+		{"}", "abc", "cab", ""},
+		{"$1", "abc", "abc1", ""},
+		{"$ ", "abc", "abc ", ""},
+		{"^x", "abc", "xabc", ""},
+		{"@a", "banana", "bnn", ""},
+		{"T1", "abc", "aBc", ""},
+		{"T9", "abc", "abc", ""},
+		{"TA", "abcdefghijk", "abcdefghijK", ""},
+		{"D1", "abc", "ac", ""},
+		{"'2", "abcd", "ab", ""},
+		{"sa4", "banana", "b4n4n4", ""},
+		{"i1x", "abc", "axbc", ""},
+		{"i3x", "abc", "abcx", ""},
+		{"i5x", "abc", "abc", ""},
+		{"o0X", "abc", "Xbc", ""},
+		{":", "abc", "abc", ""},
+		{"c $1 $2 $3", "admin", "Admin123", ""},
+		{"^. $. $s $w $p", "backup.php", ".backup.php.swp", ""},
+		{"$", "", "", "Missing argument of rule function '$'"},
+		{"sa", "", "", "Missing argument of rule function 's'"},
+		{"o", "", "", "Missing argument of rule function 'o'"},
+		{"Ta", "", "", "Invalid position 'a'"},
+		{"ixy", "", "", "Invalid position 'x'"},
+		{"u x", "", "", "Unsupported rule function 'x'"},
+	}
+
+	for _, tt := range tests {
+		rule, err := parseHashcatRule(tt.rule)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("parseHashcatRule(%s) = %v, want an error with '%s'", tt.rule, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseHashcatRule(%s) failed: %s", tt.rule, err)
+			continue
+		}
+
+		if got := rule.Apply(tt.word); len(got) != 1 || got[0] != tt.want {
+			t.Errorf("%s applied to %s = %q, want %s", tt.rule, tt.word, got, tt.want)
+		}
+	}
+}
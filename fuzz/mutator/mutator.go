@@ -0,0 +1,194 @@
+package mutator
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// Rule creates variants of a word, e.g. backup.php -> backup.php.bak
+type Rule interface {
+	// Apply returns the variants of a word. A variant may be equal to the word itself.
+	Apply(word string) []string
+	// NumVariants returns the maximum number of variants per word.
+	NumVariants() int
+}
+
+// Rules is a set of rules. The variants of all rules are combined.
+type Rules []Rule
+
+// Specs lists all supported rule specs.
+var Specs = []string{
+	"prefix:.,_",
+	"suffix:~,.bak",
+	"case",
+	"ext:bak,old",
+	"rule:<hashcat rule>",
+	"rules:<hashcat rule file>",
+	"backup",
+}
+
+// Parse creates the rules of a spec. Example: suffix:~,.bak,.old
+func Parse(spec string) (Rules, error) {
+	name, arg := spec, ""
+	if i := strings.Index(spec, ":"); i != -1 {
+		name, arg = spec[:i], spec[i+1:]
+	}
+
+	switch name {
+	case "prefix":
+		return Rules{affix{values: strings.Split(arg, ","), prefix: true}}, nil
+	case "suffix":
+		return Rules{affix{values: strings.Split(arg, ",")}}, nil
+	case "case":
+		return Rules{caseVariants{}}, nil
+	case "ext":
+		return Rules{extSwap{exts: strings.Split(arg, ",")}}, nil
+	case "rule":
+		r, err := parseHashcatRule(arg)
+		if err != nil {
+			return nil, err
+		}
+		return Rules{r}, nil
+	case "rules":
+		return parseHashcatRuleFile(arg)
+	case "backup":
+		return backupRules(), nil
+	}
+
+	return nil, fmt.Errorf("Unknown mutation rule '%s'. Supported: %s", spec, strings.Join(Specs, ", "))
+}
+
+// Mutate returns all distinct variants of a word in the order of the rules.
+// The word itself is not part of the variants.
+func (rs Rules) Mutate(word string) []string {
+	seen := map[string]bool{word: true}
+	variants := []string{}
+
+	for _, r := range rs {
+		for _, v := range r.Apply(word) {
+			if !seen[v] {
+				seen[v] = true
+				variants = append(variants, v)
+			}
+		}
+	}
+
+	return variants
+}
+
+// NumVariants returns the maximum number of variants per word.
+func (rs Rules) NumVariants() int {
+	n := 0
+	for _, r := range rs {
+		n += r.NumVariants()
+	}
+
+	return n
+}
+
+// backupRules finds typical backup and temporary files of editors,
+// e.g. for backup.php: backup.php~, backup.php.bak, .backup.php.swp, backup.old.php, Backup.php
+func backupRules() Rules {
+	vimSwap, _ := parseHashcatRule("^. $. $s $w $p")
+
+	return Rules{
+		affix{values: []string{"~", ".bak", ".old", ".orig", ".save", ".tmp", ".1", "_backup"}},
+		vimSwap,
+		extSwap{exts: []string{"bak", "old"}},
+		caseVariants{},
+	}
+}
+
+// affix adds a prefix or suffix to a word.
+type affix struct {
+	values []string
+	prefix bool
+}
+
+func (a affix) Apply(word string) []string {
+	variants := []string{}
+	for _, v := range a.values {
+		if a.prefix {
+			variants = append(variants, v+word)
+		} else {
+			variants = append(variants, word+v)
+		}
+	}
+
+	return variants
+}
+
+func (a affix) NumVariants() int { return len(a.values) }
+
+// caseVariants creates the lower case, upper case and capitalized variant of a word.
+type caseVariants struct{}
+
+func (caseVariants) Apply(word string) []string {
+	return []string{strings.ToLower(word), strings.ToUpper(word), capitalize(word)}
+}
+
+func (caseVariants) NumVariants() int { return 3 }
+
+// extSwap replaces the extension of a word and inserts an extension in front of it,
+// e.g. backup.php -> backup.old, backup.old.php
+type extSwap struct {
+	exts []string
+}
+
+func (e extSwap) Apply(word string) []string {
+	ext := path.Ext(word)
+	if ext == "" || ext == word {
+		return nil
+	}
+	name := strings.TrimSuffix(word, ext)
+
+	variants := []string{}
+	for _, x := range e.exts {
+		x = "." + strings.TrimPrefix(x, ".")
+		variants = append(variants, name+x, name+x+ext)
+	}
+
+	return variants
+}
+
+func (e extSwap) NumVariants() int { return 2 * len(e.exts) }
+
+// parseHashcatRuleFile reads a hashcat rule file with one rule per line.
+// Comment lines (#) and blank lines are skipped.
+func parseHashcatRuleFile(file string) (Rules, error) {
+	fh, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read rule file: %s", err)
+	}
+	defer fh.Close()
+
+	rules := Rules{}
+	s := bufio.NewScanner(fh)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		r, err := parseHashcatRule(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s in '%s'", err, file)
+		}
+		rules = append(rules, r)
+	}
+
+	return rules, s.Err()
+}
+
+func capitalize(word string) string {
+	r := []rune(strings.ToLower(word))
+	if len(r) > 0 {
+		r[0] = unicode.ToUpper(r[0])
+	}
+
+	return string(r)
+}
@@ -0,0 +1,75 @@
+package mutator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gofuzzy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ruleFile := filepath.Join(dir, "rules.txt")
+	badRuleFile := filepath.Join(dir, "bad.txt")
+	if err := ioutil.WriteFile(ruleFile, []byte("# Comment\n\nu\n$1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(badRuleFile, []byte("u\n$\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		spec        string
+		word        string
+		want        []string
+		numVariants int
+		err         string
+	}{
+		{"prefix:.,_", "admin", []string{".admin", "_admin"}, 2, ""},
+		{"suffix:~,.bak", "index.php", []string{"index.php~", "index.php.bak"}, 2, ""},
+		{"case", "Admin", []string{"admin", "ADMIN"}, 3, ""}, // The word itself is not a variant
+		{"ext:bak,.old", "index.php", []string{"index.bak", "index.bak.php", "index.old", "index.old.php"}, 4, ""},
+		{"ext:bak", "admin", []string{}, 2, ""},
+		{"ext:bak", ".htaccess", []string{}, 2, ""},
+		{"rule:u", "admin", []string{"ADMIN"}, 1, ""},
+		{"rules:" + ruleFile, "admin", []string{"ADMIN", "admin1"}, 2, ""},
+		{"backup", "index.php", []string{
+			"index.php~", "index.php.bak", "index.php.old", "index.php.orig", "index.php.save", "index.php.tmp", "index.php.1", "index.php_backup",
+			".index.php.swp",
+			"index.bak", "index.bak.php", "index.old", "index.old.php",
+			"INDEX.PHP", "Index.php",
+		}, 16, ""},
+		{"rot13", "", nil, 0, "Unknown mutation rule 'rot13'"},
+		{"rule:x", "", nil, 0, "Unsupported rule function 'x'"},
+		{"rules:" + filepath.Join(dir, "missing.txt"), "", nil, 0, "Unable to read rule file"},
+		{"rules:" + badRuleFile, "", nil, 0, "Missing argument of rule function '$' in '$' in '" + badRuleFile + "'"},
+	}
+
+	for _, tt := range tests {
+		rules, err := Parse(tt.spec)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Parse(%s) = %v, want an error with '%s'", tt.spec, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%s) failed: %s", tt.spec, err)
+			continue
+		}
+
+		if got := rules.Mutate(tt.word); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%s).Mutate(%s) = %q, want %q", tt.spec, tt.word, got, tt.want)
+		}
+		if rules.NumVariants() != tt.numVariants {
+			t.Errorf("Parse(%s).NumVariants() = %d, want %d", tt.spec, rules.NumVariants(), tt.numVariants)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package opts
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/shellrausch/gofuzzy/fuzz/dns"
+)
+
+// validateDNS checks the options of the DNS mode. Only the hostname of the URL is resolved,
+// hence all keywords must be placed in it.
+func (o *Opts) validateDNS() error {
+	if o.RawRequest != nil || o.VHost {
+		return fmt.Errorf("The DNS mode is not supported with a raw request or -vhost")
+	}
+
+	if o.Recursive || o.FileExtensionsRaw != "" {
+		return fmt.Errorf("Recursion and extensions with -x are not supported in DNS mode")
+	}
+
+	if o.ReplayProxyRaw != "" {
+		return fmt.Errorf("A replay proxy is not supported in DNS mode")
+	}
+
+	// With a single wordlist the payload is prepended as subdomain, if the keyword is missing.
+	for _, kw := range o.Wordlists.Keywords() {
+		if len(o.Wordlists) > 1 && !strings.Contains(o.URLRaw, kw) {
+			return fmt.Errorf("The keyword %s must be in the hostname in DNS mode. Example: -u %s.example.com", kw, kw)
+		}
+	}
+
+	if o.DNSHTTP != "" && o.DNSHTTP != "http" && o.DNSHTTP != "https" {
+		return fmt.Errorf("Invalid protocol %s for -dns-http. Supported: http, https", o.DNSHTTP)
+	}
+
+	_, err := parseResolvers(o.ResolversRaw, ",")
+
+	return err
+}
+
+// parseResolvers parses DNS servers separated by comma or a file with one server per line.
+func parseResolvers(raw, sep string) ([]string, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	servers := strings.Split(raw, sep)
+	if _, err := os.Stat(raw); err == nil {
+		content, err := ioutil.ReadFile(raw)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read the resolvers: %s", err)
+		}
+		servers = strings.Split(string(content), "\n")
+	}
+
+	resolvers := []string{}
+	for _, s := range servers {
+		s = strings.TrimSpace(s)
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+
+		host, _, err := net.SplitHostPort(dns.ServerAddr(s))
+		if err != nil || net.ParseIP(host) == nil {
+			return nil, fmt.Errorf("Invalid resolver '%s'. Use an IP address with an optional port. Example: 1.1.1.1 or 127.0.0.1:5353", s)
+		}
+		resolvers = append(resolvers, s)
+	}
+
+	if len(resolvers) == 0 {
+		return nil, fmt.Errorf("No resolvers found in '%s'", raw)
+	}
+
+	return resolvers, nil
+}
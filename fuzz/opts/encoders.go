@@ -0,0 +1,54 @@
+package opts
+
+import (
+	"strings"
+
+	"github.com/shellrausch/gofuzzy/fuzz/encoder"
+)
+
+// allKeywords binds an encoder chain to every keyword without an own chain.
+const allKeywords = ""
+
+// Encoders implements flag.Value, so that -e can be passed multiple times.
+// Every value is an encoder chain, optionally bound to a keyword.
+// Example: -e urlencode -e PASS:md5,upper
+type Encoders []string
+
+func (e *Encoders) String() string {
+	return strings.Join(*e, " ")
+}
+
+// Set adds an encoder chain in the format [KEYWORD:]encoder[,encoder...].
+func (e *Encoders) Set(v string) error {
+	if _, _, err := parseEncoderChain(v); err != nil {
+		return err
+	}
+
+	*e = append(*e, v)
+
+	return nil
+}
+
+// chains returns the encoder chains by keyword. A chain for all keywords has an empty keyword.
+func (e Encoders) chains() map[string]encoder.Chain {
+	chains := map[string]encoder.Chain{}
+	for _, v := range e {
+		kw, chain, _ := parseEncoderChain(v)
+		chains[kw] = chain
+	}
+
+	return chains
+}
+
+// parseEncoderChain splits an optional keyword from an encoder chain and parses the chain.
+// Encoder names contain no colon, hence everything in front of it is the keyword.
+func parseEncoderChain(v string) (string, encoder.Chain, error) {
+	kw := allKeywords
+	if i := strings.Index(v, ":"); i != -1 {
+		kw, v = v[:i], v[i+1:]
+	}
+
+	chain, err := encoder.ParseChain(v)
+
+	return kw, chain, err
+}
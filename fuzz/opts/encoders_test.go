@@ -0,0 +1,61 @@
+package opts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseEncoderChain(t *testing.T) {
+	tests := []struct {
+		v    string
+		kw   string
+		want string // Encoded "a b"
+		err  string
+	}{
+		{"urlencode", allKeywords, "a%20b", ""},
+		{"PASS:md5", "PASS", "0cc9cd4dd26c5137b675a0d819cb9ab0", ""},
+		{"PASS:urlencode,base64", "PASS", "YSUyMGI=", ""},
+		{":upper", allKeywords, "A B", ""},
+		{"PASS:", "PASS", "", "Unknown encoder ''"},
+		{"PASS:rot13", "PASS", "", "Unknown encoder 'rot13'"},
+		{"PASS:md5:upper", "PASS", "", "Unknown encoder 'md5:upper'"},
+	}
+
+	for _, tt := range tests {
+		kw, chain, err := parseEncoderChain(tt.v)
+		switch {
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("parseEncoderChain(%s) = %v, want an error with '%s'", tt.v, err, tt.err)
+		case tt.err == "" && err != nil:
+			t.Errorf("parseEncoderChain(%s) failed: %s", tt.v, err)
+		case kw != tt.kw:
+			t.Errorf("parseEncoderChain(%s) = keyword %s, want %s", tt.v, kw, tt.kw)
+		case tt.err == "" && chain.Encode("a b") != tt.want:
+			t.Errorf("parseEncoderChain(%s).Encode(a b) = %s, want %s", tt.v, chain.Encode("a b"), tt.want)
+		}
+	}
+}
+
+func TestEncoderChains(t *testing.T) {
+	wl, remove := tempWordlist(t)
+	defer remove()
+
+	o := New()
+	args := []string{"-u", "http://127.0.0.1/USER/PASS/ID", "-w", wl + ":USER", "-w", wl + ":PASS", "-w", wl + ":ID", "-e", "upper", "-e", "PASS:hex"}
+	if err := o.ParseArgs(map[string]bool{}, args); err != nil {
+		t.Fatal(err)
+	}
+
+	// A chain bound to the keyword takes precedence over the chain for all keywords.
+	want := map[string]string{"USER": "ADMIN", "PASS": "61646d696e", "ID": "ADMIN"}
+	for kw, w := range want {
+		if got := o.EncoderChains[kw].Encode("admin"); got != w {
+			t.Errorf("The chain of %s encodes admin to %s, want %s", kw, got, w)
+		}
+	}
+
+	err := New().ParseArgs(map[string]bool{}, []string{"-u", "http://127.0.0.1/USER", "-w", wl + ":USER", "-e", "PASS:hex"})
+	if err == nil || !strings.Contains(err.Error(), "not bound to a wordlist") {
+		t.Errorf("ParseArgs with the chain of an unknown keyword = %v, want an error", err)
+	}
+}
@@ -0,0 +1,45 @@
+package opts
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// HeaderRegex is a regex which is matched against the values of a single header field.
+type HeaderRegex struct {
+	Name  string
+	Regex *regexp.Regexp
+}
+
+// MatchHeader checks if any value of the header field matches the regex.
+func (hr *HeaderRegex) MatchHeader(h http.Header) bool {
+	for _, v := range h[http.CanonicalHeaderKey(hr.Name)] {
+		if hr.Regex.MatchString(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseHeaderRegex parses a header regex in the format Name:regex.
+// An empty string results in a nil HeaderRegex.
+func parseHeaderRegex(raw string) (*HeaderRegex, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	sepIndex := strings.Index(raw, ":")
+	if sepIndex < 1 {
+		return nil, fmt.Errorf("Malformed header regex '%s'. Missing header name, like Server:nginx", raw)
+	}
+
+	re, err := regexp.Compile(strings.TrimSpace(raw[sepIndex+1:]))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid regex in '%s'. %s", raw, err)
+	}
+
+	return &HeaderRegex{Name: strings.TrimSpace(raw[:sepIndex]), Regex: re}, nil
+}
@@ -0,0 +1,37 @@
+package opts
+
+import (
+	"strings"
+
+	"github.com/shellrausch/gofuzzy/fuzz/mutator"
+)
+
+// Mutations implements flag.Value, so that -mutate can be passed multiple times.
+// Example: -mutate suffix:~,.bak -mutate case
+type Mutations []string
+
+func (m *Mutations) String() string {
+	return strings.Join(*m, " ")
+}
+
+// Set adds a mutation rule spec.
+func (m *Mutations) Set(v string) error {
+	if _, err := mutator.Parse(v); err != nil {
+		return err
+	}
+
+	*m = append(*m, v)
+
+	return nil
+}
+
+// rules returns the rules of all specs.
+func (m Mutations) rules() mutator.Rules {
+	rules := mutator.Rules{}
+	for _, v := range m {
+		r, _ := mutator.Parse(v)
+		rules = append(rules, r...)
+	}
+
+	return rules
+}
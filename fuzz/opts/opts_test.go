@@ -0,0 +1,78 @@
+package opts
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// tempWordlist creates a wordlist with a single payload. It returns the path of the
+// wordlist and a function to remove it.
+func tempWordlist(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "gofuzzy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wl := filepath.Join(dir, "wl.txt")
+	if err := ioutil.WriteFile(wl, []byte("admin\n"), 0644); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	return wl, func() { os.RemoveAll(dir) }
+}
+
+// testValidate parses the args of every test and checks that the error contains the
+// expected text. An empty text expects no error.
+func testValidate(t *testing.T, tests []struct {
+	args []string
+	err  string
+}) {
+	for _, tt := range tests {
+		err := New().ParseArgs(map[string]bool{}, tt.args)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("ParseArgs(%v) failed: %s", tt.args, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("ParseArgs(%v) = %v, want an error with '%s'", tt.args, err, tt.err)
+		}
+	}
+}
+
+func TestValidateKeywords(t *testing.T) {
+	wl, remove := tempWordlist(t)
+	defer remove()
+
+	tests := []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"-u", "http://127.0.0.1/USER/PASS", "-w", wl + ":USER", "-w", wl + ":PASS"}, ""},
+		{[]string{"-u", "http://127.0.0.1/FUZZ/FUZZ2", "-w", wl, "-w", wl + ":FUZZ2"}, "start alike"},
+		{[]string{"-u", "http://127.0.0.1/FUZZ2/FUZZ", "-w", wl + ":FUZZ2", "-w", wl}, "start alike"},
+		{[]string{"-u", "http://127.0.0.1/FUZZ/FUZZ", "-w", wl, "-w", wl}, "more than one wordlist"},
+		{[]string{"-u", "http://127.0.0.1/FUZZ", "-w", wl, "-w", wl + ":PASS"}, "not found"},
+	}
+
+	testValidate(t, tests)
+}
+
+func TestValidateHeader(t *testing.T) {
+	wl, remove := tempWordlist(t)
+	defer remove()
+
+	tests := []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"-u", "http://127.0.0.1/", "-w", wl, "-H", "User-Agent:Chrome,Cookie:Session=abcd"}, ""},
+		{[]string{"-u", "http://127.0.0.1/", "-w", wl, "-H", "X-Empty:"}, ""},
+		{[]string{"-u", "http://127.0.0.1/", "-w", wl, "-H", "User-Agent Chrome"}, "Malformed header field 'User-Agent Chrome'"},
+		{[]string{"-u", "http://127.0.0.1/", "-w", wl, "-H", "User-Agent:Chrome,Session=abcd"}, "Malformed header field 'Session=abcd'"},
+	}
+
+	testValidate(t, tests)
+}
@@ -0,0 +1,60 @@
+package opts
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// loadRawRequest loads a raw HTTP/1.1 request template (e.g. saved from Burp).
+// The target URL is derived from the request line and the Host header.
+func (o *Opts) loadRawRequest() error {
+	if o.URLRaw != "" {
+		return fmt.Errorf("Provide either a URL with -u or a raw request with -request")
+	}
+
+	if o.RequestProto != "http" && o.RequestProto != "https" {
+		return fmt.Errorf("Invalid protocol %s for the raw request. Supported: http, https", o.RequestProto)
+	}
+
+	raw, err := ioutil.ReadFile(o.RequestFile)
+	if err != nil {
+		return fmt.Errorf("Unable to read the raw request: %s", err)
+	}
+	o.RawRequest = raw
+
+	req, _, err := ParseRawRequest(string(raw))
+	if err != nil {
+		return fmt.Errorf("Unable to parse the raw request '%s': %s", o.RequestFile, err)
+	}
+
+	o.URLRaw = o.RequestProto + "://" + req.Host + req.URL.RequestURI()
+	if req.URL.IsAbs() {
+		o.URLRaw = req.URL.String()
+	}
+
+	return nil
+}
+
+// ParseRawRequest parses the head of a raw HTTP request and returns the body separately.
+// The body is not parsed by the length in the Content-Length header, since the length
+// changes when a keyword in the body is replaced by a payload.
+func ParseRawRequest(raw string) (*http.Request, string, error) {
+	head, body := raw, ""
+	if i := strings.Index(raw, "\r\n\r\n"); i != -1 {
+		head, body = raw[:i], raw[i+4:]
+	} else if i := strings.Index(raw, "\n\n"); i != -1 {
+		head, body = raw[:i], raw[i+2:]
+	}
+
+	req, err := http.ReadRequest(bufio.NewReader(bytes.NewBufferString(head + "\r\n\r\n")))
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Del("Content-Length")
+
+	return req, body, nil
+}
@@ -0,0 +1,193 @@
+package opts
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/shellrausch/gofuzzy/fuzz/generator"
+)
+
+// Attack modes which define how the payloads of multiple wordlists are combined.
+const (
+	// ModeSniper fuzzes one keyword after the other. All other keywords are left empty.
+	ModeSniper = "sniper"
+	// ModePitchfork zips the wordlists line by line and stops with the shortest wordlist.
+	ModePitchfork = "pitchfork"
+	// ModeClusterbomb tries every combination of all wordlists (cartesian product).
+	ModeClusterbomb = "clusterbomb"
+)
+
+// DefaultFuzzKeyword is the keyword of a wordlist which is passed without an explicit keyword.
+const DefaultFuzzKeyword = "FUZZ"
+
+// Stdin is the wordlist file name which reads the payloads from stdin.
+const Stdin = "-"
+
+// Wordlist binds one or more wordlist files to the keyword which gets replaced by its payloads.
+// Instead of files the payloads can be produced by a generator.
+type Wordlist struct {
+	Files        []string // Concatenated with duplicates removed. Files ending in .gz or .zst are decompressed.
+	Generator    generator.Generator
+	Keyword      string
+	LineCount    uint
+	SkipComments bool // Skips comment lines (#) and blank lines
+}
+
+// Name returns the file names or the spec of the generator.
+func (wl *Wordlist) Name() string {
+	if wl.Generator != nil {
+		return wl.Generator.String()
+	}
+
+	return strings.Join(wl.Files, ",")
+}
+
+// IsStdin checks if the wordlist is read from stdin. Stdin can be read only once
+// and the number of payloads is unknown.
+func (wl *Wordlist) IsStdin() bool {
+	return len(wl.Files) == 1 && wl.Files[0] == Stdin
+}
+
+// Open starts reading the payloads from the beginning.
+func (wl *Wordlist) Open() (generator.Iterator, error) {
+	if wl.Generator != nil {
+		return wl.Generator.Iterate(), nil
+	}
+
+	for _, f := range wl.Files {
+		if _, err := os.Stat(f); f != Stdin && err != nil {
+			return nil, err
+		}
+	}
+
+	// Every payload of the concatenated files is kept in memory to remove the duplicates.
+	it := &lineIterator{files: wl.Files, skipComments: wl.SkipComments}
+	if len(wl.Files) > 1 {
+		it.seen = map[string]bool{}
+	}
+
+	return it, nil
+}
+
+// count returns the number of payloads. A generator computes it without producing the payloads.
+// The payloads from stdin can't be counted in advance, hence the count is 0.
+func (wl *Wordlist) count() uint {
+	if wl.Generator != nil {
+		return wl.Generator.Count()
+	}
+
+	if wl.IsStdin() {
+		return 0
+	}
+
+	it, err := wl.Open()
+	if err != nil {
+		return 0
+	}
+	defer it.Close()
+
+	var n uint
+	for _, ok := it.Next(); ok; _, ok = it.Next() {
+		n++
+	}
+
+	return n
+}
+
+// Wordlists implements flag.Value, so that -w can be passed multiple times.
+// Example: -w users.txt:USER -w pass.txt:PASS
+type Wordlists []*Wordlist
+
+func (w *Wordlists) String() string {
+	s := []string{}
+	for _, wl := range *w {
+		s = append(s, wl.Name()+":"+wl.Keyword)
+	}
+
+	return strings.Join(s, ",")
+}
+
+// Set parses a wordlist argument in the format file[,file...][:KEYWORD].
+func (w *Wordlists) Set(v string) error {
+	wl := &Wordlist{Keyword: DefaultFuzzKeyword}
+
+	// The keyword is only split off if it looks like one. This way file names
+	// containing a colon (e.g. C:\wl.txt) are still accepted.
+	files := v
+	if i := strings.LastIndex(v, ":"); i != -1 && isKeywordFormatValid(v[i+1:]) {
+		files, wl.Keyword = v[:i], v[i+1:]
+	}
+
+	wl.Files = strings.Split(files, ",")
+	for _, f := range wl.Files {
+		if f == "" {
+			return fmt.Errorf("Empty wordlist file name in '%s'", v)
+		}
+
+		if f == Stdin && len(wl.Files) > 1 {
+			return fmt.Errorf("Stdin (-) can't be concatenated with other wordlists in '%s'", v)
+		}
+	}
+
+	*w = append(*w, wl)
+
+	return nil
+}
+
+// generatorValue implements flag.Value for -z, so that generators are added
+// to the wordlists in the order they were passed, together with -w.
+// Example: -z range:1-100:pad=3:ID
+type generatorValue Wordlists
+
+func (g *generatorValue) String() string {
+	return (*Wordlists)(g).String()
+}
+
+// Set parses a generator argument in the format type:spec[:option=value...][:KEYWORD].
+func (g *generatorValue) Set(v string) error {
+	wl := &Wordlist{Keyword: DefaultFuzzKeyword}
+
+	// A keyword is only split off behind the spec, e.g. list:admin,root:USER
+	spec := v
+	if parts := strings.Split(v, ":"); len(parts) > 2 && isKeywordFormatValid(parts[len(parts)-1]) {
+		wl.Keyword = parts[len(parts)-1]
+		spec = strings.Join(parts[:len(parts)-1], ":")
+	}
+
+	gen, err := generator.Parse(spec)
+	if err != nil {
+		return err
+	}
+	wl.Generator = gen
+
+	*g = append(*g, wl)
+
+	return nil
+}
+
+// Keywords returns the keywords of all wordlists in the order they were passed.
+func (w Wordlists) Keywords() []string {
+	kws := []string{}
+	for _, wl := range w {
+		kws = append(kws, wl.Keyword)
+	}
+
+	return kws
+}
+
+// isKeywordFormatValid checks if a keyword has a valid format: [A-Za-z0-9_]+
+func isKeywordFormatValid(kw string) bool {
+	if kw == "" {
+		return false
+	}
+
+	for _, letter := range kw {
+		if !unicode.IsLetter(letter) && !unicode.IsDigit(letter) && letter != '_' {
+			return false
+		}
+	}
+
+	return true
+}
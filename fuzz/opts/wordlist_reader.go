@@ -0,0 +1,174 @@
+package opts
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// lineIterator returns the lines of one or more wordlist files one after the other.
+type lineIterator struct {
+	files        []string
+	skipComments bool
+	seen         map[string]bool // Only set if duplicates of concatenated files are removed. Holds every payload.
+
+	r io.ReadCloser
+	s *bufio.Scanner
+}
+
+func (it *lineIterator) Next() (string, bool) {
+	for {
+		if it.s == nil {
+			if len(it.files) == 0 {
+				return "", false
+			}
+
+			r, err := openWordlistFile(it.files[0])
+			it.files = it.files[1:]
+			if err != nil {
+				log.Printf("Unable to open wordlist: %s", err)
+				continue
+			}
+			it.r, it.s = r, bufio.NewScanner(r)
+		}
+
+		if !it.s.Scan() {
+			if err := it.s.Err(); err != nil {
+				log.Printf("Unable to read wordlist: %s", err)
+			}
+			if err := it.Close(); err != nil {
+				log.Print(err)
+			}
+			continue
+		}
+
+		line := it.s.Text()
+
+		if it.skipComments {
+			if trimmed := strings.TrimSpace(line); trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+		}
+
+		if it.seen != nil {
+			if it.seen[line] {
+				continue
+			}
+			it.seen[line] = true
+		}
+
+		return line, true
+	}
+}
+
+// Close closes the file which is read at the moment.
+func (it *lineIterator) Close() error {
+	if it.r == nil {
+		return nil
+	}
+
+	err := it.r.Close()
+	it.r, it.s = nil, nil
+
+	return err
+}
+
+// openWordlistFile opens a wordlist file. Stdin is read for "-". Files ending in .gz are
+// decompressed with gzip, files ending in .zst with the zstd binary, which must be in the PATH.
+func openWordlistFile(name string) (io.ReadCloser, error) {
+	if name == Stdin {
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+
+	if strings.HasSuffix(name, ".zst") {
+		return openZstd(name)
+	}
+
+	fh, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasSuffix(name, ".gz") {
+		return fh, nil
+	}
+
+	gz, err := gzip.NewReader(fh)
+	if err != nil {
+		fh.Close()
+		return nil, fmt.Errorf("Unable to decompress '%s': %s", name, err)
+	}
+
+	return &gzipFile{Reader: gz, fh: fh}, nil
+}
+
+// gzipFile closes the gzip reader and the underlying file at once.
+type gzipFile struct {
+	*gzip.Reader
+	fh *os.File
+}
+
+func (g *gzipFile) Close() error {
+	g.Reader.Close()
+	return g.fh.Close()
+}
+
+// zstdFile reads the output of the zstd binary, which decompresses the file.
+type zstdFile struct {
+	io.ReadCloser
+	cmd    *exec.Cmd
+	name   string
+	stderr bytes.Buffer // Read only after zstd exited
+	eof    bool
+}
+
+func openZstd(name string) (io.ReadCloser, error) {
+	z := &zstdFile{cmd: exec.Command("zstd", "-dcq", name), name: name}
+	// -q suppresses only the progress, the errors are still written.
+	z.cmd.Stderr = &z.stderr
+
+	out, err := z.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := z.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("Unable to decompress '%s'. The zstd binary is required for .zst wordlists: %s", name, err)
+	}
+	z.ReadCloser = out
+
+	return z, nil
+}
+
+func (z *zstdFile) Read(p []byte) (int, error) {
+	n, err := z.ReadCloser.Read(p)
+	if err == io.EOF {
+		z.eof = true
+	}
+
+	return n, err
+}
+
+// Close stops the decompression, if the file was not read completely. Otherwise it
+// reports, if zstd failed, e.g. because the file is truncated or no zstd file at all.
+func (z *zstdFile) Close() error {
+	if !z.eof {
+		z.cmd.Process.Kill()
+		z.cmd.Wait()
+		return nil
+	}
+
+	if err := z.cmd.Wait(); err != nil {
+		msg := strings.SplitN(strings.TrimSpace(z.stderr.String()), "\n", 2)[0]
+		return fmt.Errorf("Unable to decompress '%s': %s (%s)", z.name, msg, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package opts
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestZstdFile(t *testing.T) {
+	if _, err := exec.LookPath("zstd"); err != nil {
+		t.Skip("The zstd binary is not in the PATH")
+	}
+
+	dir, err := ioutil.TempDir("", "gofuzzy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	wl := filepath.Join(dir, "wl.txt")
+	if err := ioutil.WriteFile(wl, []byte(strings.Repeat("admin\nlogin\n", 1000)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("zstd", "-q", wl).CombinedOutput(); err != nil {
+		t.Fatalf("zstd failed: %s %s", err, out)
+	}
+
+	b, err := ioutil.ReadFile(wl + ".zst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	truncated := filepath.Join(dir, "truncated.txt.zst")
+	if err := ioutil.WriteFile(truncated, b[:len(b)-4], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		file    string
+		readAll bool
+		err     string
+	}{
+		{wl + ".zst", true, ""},
+		{wl + ".zst", false, ""},
+		{truncated, true, "Unable to decompress"},
+		{truncated, false, ""}, // Stopped early, the error is not noticed
+		{wl, true, "Unable to decompress"},
+	}
+
+	for _, tt := range tests {
+		r, err := openZstd(tt.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tt.readAll {
+			ioutil.ReadAll(r)
+		} else {
+			r.Read(make([]byte, 1))
+		}
+
+		err = r.Close()
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("Close(%s) failed: %s", filepath.Base(tt.file), err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("Close(%s) = %v, want an error with '%s'", filepath.Base(tt.file), err, tt.err)
+		}
+	}
+}
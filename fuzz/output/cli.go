@@ -2,40 +2,64 @@ package output
 
 import (
 	"fmt"
-	"os"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/shellrausch/gofuzzy/fuzz/client"
 )
 
-type cli struct{}
-
-var tableWriter *tabwriter.Writer
+type cli struct {
+	calibratedFilters []string
+	tableWriter       *tabwriter.Writer
+}
 
-func (cli) init() {
+func (c cli) init() {
 	fmt.Println(banner)
-	tableWriter = new(tabwriter.Writer)
-	tableWriter.Init(os.Stdout, 13, 0, 0, ' ', 0)
 
-	fmt.Fprintln(tableWriter, "---------------------------------------------------------------------------------")
-	fmt.Fprintln(tableWriter, "Chars(-hh) \t Words(-hw) \t Lines(-hl) \t Header(-hr) \t Code(-hc) \t Payload")
-	fmt.Fprintln(tableWriter, "---------------------------------------------------------------------------------")
+	if len(c.calibratedFilters) > 0 {
+		fmt.Println("Calibrated filters: " + strings.Join(c.calibratedFilters, ", "))
+	}
+
+	fmt.Fprintln(c.tableWriter, "-----------------------------------------------------------------------------------------------------------")
+	fmt.Fprintln(c.tableWriter, "Chars(-hh) \t Words(-hw) \t Lines(-hl) \t Header(-hr) \t Code(-hc) \t TTFB \t Time(-ht) \t Payload")
+	fmt.Fprintln(c.tableWriter, "-----------------------------------------------------------------------------------------------------------")
 }
 
-func (cli) write(r *client.Result) {
-	o := fmt.Sprintf("%d \t %d \t %d \t %d \t %d \t %s", r.ContentLength, r.NumWords, r.NumLines, r.HeaderSize, r.StatusCode, r.Payload)
-	fmt.Fprintln(tableWriter, o)
-	tableWriter.Flush()
+func (c cli) write(r *client.Result) {
+	o := fmt.Sprintf("%d \t %d \t %d \t %d \t %d \t %dms \t %dms \t %s", r.ContentLength, r.NumWords, r.NumLines, r.HeaderSize, r.StatusCode, r.TTFB, r.Duration, payloadString(r)+recordsString(r))
+	fmt.Fprintln(c.tableWriter, o)
+	c.tableWriter.Flush()
 }
 
 func (cli) writeProgress(p *client.Progress) {
+	throttle := ""
+	if p.Throttle != "" {
+		throttle = " [" + p.Throttle + "]"
+	}
+
+	if p.TotalUnknown {
+		fmt.Printf("\r%50s\r%d/?%s\r", "", p.NumDoneRequests, throttle) // Output: 123/? [throttled 4/8]
+		return
+	}
+
 	percent := int((float64(p.NumDoneRequests) / float64(p.NumApproxRequests)) * 100)
-	fmt.Printf("\r%30s\r~%d/%d (%d%%)\r", "", p.NumDoneRequests, p.NumApproxRequests, percent) // Output: ~123/9000 (2%)
+	fmt.Printf("\r%50s\r~%d/%d (%d%%)%s\r", "", p.NumDoneRequests, p.NumApproxRequests, percent, throttle) // Output: ~123/9000 (2%) [throttled 4/8]
 }
 
-func (cli) close() {
+func (cli) close(s *client.Summary) {
 	// Just clear the last progress output with some whitespaces.
-	fmt.Printf("\r%30s\r", "")
+	fmt.Printf("\r%50s\r", "")
+
+	state := "Finished"
+	if s.Interrupted {
+		state = "Interrupted"
+	}
+	fmt.Printf("%s after %s: %d requests, %d results\n", state, s.Duration.Round(time.Millisecond), s.NumDoneRequests, s.NumResults)
+
+	if s.NumSimilar > 0 {
+		fmt.Printf("%d similar results hidden\n", s.NumSimilar)
+	}
 }
 
 var banner = `                                             
@@ -12,14 +12,14 @@ type csv struct {
 }
 
 func (c csv) init() {
-	o := fmt.Sprintf("%s;%s;%s;%s;%s;%s", "Content-Length", "Words", "Lines", "Header", "Status-Code", "Payload")
+	o := fmt.Sprintf("%s;%s;%s;%s;%s;%s;%s;%s", "Content-Length", "Words", "Lines", "Header", "Status-Code", "TTFB-ms", "Duration-ms", "Payload")
 	fmt.Fprintln(c.file, o)
 }
 
 func (c csv) write(r *client.Result) {
-	o := fmt.Sprintf("%d;%d;%d;%d;%d;%s", r.ContentLength, r.NumWords, r.NumLines, r.HeaderSize, r.StatusCode, r.Payload)
+	o := fmt.Sprintf("%d;%d;%d;%d;%d;%d;%d;%s", r.ContentLength, r.NumWords, r.NumLines, r.HeaderSize, r.StatusCode, r.TTFB, r.Duration, payloadString(r)+recordsString(r))
 	fmt.Fprintln(c.file, o)
 }
 
 func (csv) writeProgress(p *client.Progress) {}
-func (csv) close()                           {}
+func (csv) close(s *client.Summary)          {}
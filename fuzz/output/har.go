@@ -0,0 +1,179 @@
+package output
+
+import (
+	"encoding/base64"
+	jsn "encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shellrausch/gofuzzy/fuzz/client"
+)
+
+// har writes the full request and response of every result in the HTTP Archive format 1.2,
+// which can be imported in the browser's DevTools. The entries are written as they arrive,
+// the file is complete when the output is closed.
+type har struct {
+	file       io.Writer
+	numEntries *int
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harTimings struct {
+	Send    int `json:"send"`
+	Wait    int `json:"wait"`
+	Receive int `json:"receive"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            int         `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Comment         string      `json:"comment"`
+}
+
+func (h har) init() {
+	fmt.Fprint(h.file, `{"log":{"version":"1.2","creator":{"name":"GoFuzzy","version":""},"entries":[`)
+}
+
+func (h har) write(r *client.Result) {
+	// Results of a resumed scan were saved without their requests and responses.
+	if r.Exchange == nil {
+		return
+	}
+
+	entry, err := jsn.Marshal(newHAREntry(r))
+	if err != nil {
+		log.Printf("Unable to write the HAR entry: %s", err)
+		return
+	}
+
+	if *h.numEntries > 0 {
+		fmt.Fprint(h.file, ",")
+	}
+	fmt.Fprintf(h.file, "\n%s", entry)
+	*h.numEntries++
+}
+
+func (h har) close(s *client.Summary) {
+	fmt.Fprintln(h.file, "\n]}}")
+}
+
+func (har) writeProgress(p *client.Progress) {}
+
+func newHAREntry(r *client.Result) harEntry {
+	e := r.Exchange
+
+	req := harRequest{
+		Method:      e.Method,
+		URL:         e.URL,
+		HTTPVersion: e.Proto,
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(e.RequestHeader),
+		QueryString: []harNameValue{},
+		HeadersSize: -1,
+		BodySize:    len(e.RequestBody),
+	}
+
+	if u, err := url.Parse(e.URL); err == nil {
+		for name, values := range u.Query() {
+			for _, v := range values {
+				req.QueryString = append(req.QueryString, harNameValue{name, v})
+			}
+		}
+	}
+
+	if e.RequestBody != "" {
+		req.PostData = &harPostData{MimeType: e.RequestHeader.Get("Content-Type"), Text: e.RequestBody}
+	}
+
+	content := harContent{Size: e.BodySize, MimeType: e.ResponseHeader.Get("Content-Type"), Text: string(e.ResponseBody)}
+	if !utf8.Valid(e.ResponseBody) {
+		content.Text, content.Encoding = base64.StdEncoding.EncodeToString(e.ResponseBody), "base64"
+	}
+	if len(e.ResponseBody) < e.BodySize {
+		content.Comment = fmt.Sprintf("Body cut at %d of %d bytes", len(e.ResponseBody), e.BodySize)
+	}
+
+	return harEntry{
+		StartedDateTime: e.Start.Format(time.RFC3339Nano),
+		Time:            r.Duration,
+		Request:         req,
+		Response: harResponse{
+			Status:      r.StatusCode,
+			StatusText:  strings.TrimSpace(strings.TrimPrefix(e.Status, fmt.Sprint(r.StatusCode))),
+			HTTPVersion: e.ResponseProto,
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(e.ResponseHeader),
+			Content:     content,
+			RedirectURL: e.ResponseHeader.Get("Location"),
+			HeadersSize: -1,
+			BodySize:    e.BodySize,
+		},
+		Timings: harTimings{Send: 0, Wait: r.TTFB, Receive: r.Duration - r.TTFB},
+		Comment: payloadString(r),
+	}
+}
+
+// harHeaders converts header fields to name/value pairs, sorted by name.
+func harHeaders(h http.Header) []harNameValue {
+	headers := []harNameValue{}
+	for name, values := range h {
+		for _, v := range values {
+			headers = append(headers, harNameValue{name, v})
+		}
+	}
+	sort.SliceStable(headers, func(i, j int) bool { return headers[i].Name < headers[j].Name })
+
+	return headers
+}
@@ -0,0 +1,221 @@
+package output
+
+import (
+	"html/template"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shellrausch/gofuzzy/fuzz/client"
+	"github.com/shellrausch/gofuzzy/fuzz/opts"
+)
+
+// html writes a self-contained report when the scan ends. It needs no external
+// assets, so it can be opened offline and handed out as a single file.
+type html struct {
+	file    io.Writer
+	opt     *opts.Opts
+	start   time.Time
+	results *[]*client.Result // Collected until the output is closed
+}
+
+// htmlReport contains everything which is shown in the report.
+type htmlReport struct {
+	Args              string
+	URL               string
+	Method            string
+	Mode              string
+	Wordlists         string
+	Extensions        string
+	CalibratedFilters string
+	Start             string
+	Duration          string
+	State             string
+	NumDoneRequests   uint
+	NumResults        uint
+	Codes             []htmlCode
+	Results           []htmlResult
+}
+
+// htmlCode is a bar of the status code histogram.
+type htmlCode struct {
+	Code    int
+	Count   int
+	Percent int // Width of the bar, relative to the most frequent status code
+}
+
+type htmlResult struct {
+	*client.Result
+	PayloadString string
+}
+
+func (h html) write(r *client.Result) {
+	*h.results = append(*h.results, r)
+}
+
+func (h html) close(s *client.Summary) {
+	wordlists := []string{}
+	for _, wl := range h.opt.Wordlists {
+		wordlists = append(wordlists, wl.Name())
+	}
+
+	state := "Finished"
+	if s.Interrupted {
+		state = "Interrupted"
+	}
+
+	report := htmlReport{
+		Args:              strings.Join(h.opt.Args, " "),
+		URL:               h.opt.URL.String(),
+		Method:            h.opt.HTTPMethod,
+		Mode:              h.opt.Mode,
+		Wordlists:         strings.Join(wordlists, ", "),
+		Extensions:        strings.Join(h.opt.FileExtensions, ", "),
+		CalibratedFilters: strings.Join(h.opt.CalibratedFilters, ", "),
+		Start:             h.start.Format(time.RFC1123),
+		Duration:          s.Duration.Round(time.Millisecond).String(),
+		State:             state,
+		NumDoneRequests:   s.NumDoneRequests,
+		NumResults:        s.NumResults,
+		Codes:             statusHistogram(*h.results),
+	}
+
+	for _, r := range *h.results {
+		report.Results = append(report.Results, htmlResult{Result: r, PayloadString: payloadString(r) + recordsString(r)})
+	}
+
+	if err := htmlTemplate.Execute(h.file, report); err != nil {
+		log.Printf("Unable to write the HTML report: %s", err)
+	}
+}
+
+// statusHistogram counts the results per status code, sorted by status code.
+func statusHistogram(results []*client.Result) []htmlCode {
+	counts := map[int]int{}
+	max := 0
+	for _, r := range results {
+		counts[r.StatusCode]++
+		if counts[r.StatusCode] > max {
+			max = counts[r.StatusCode]
+		}
+	}
+
+	codes := []htmlCode{}
+	for code, count := range counts {
+		codes = append(codes, htmlCode{Code: code, Count: count, Percent: count * 100 / max})
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i].Code < codes[j].Code })
+
+	return codes
+}
+
+func (html) init()                            {}
+func (html) writeProgress(p *client.Progress) {}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>GoFuzzy report - {{.URL}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.5em; }
+table { border-collapse: collapse; }
+th, td { padding: 0.3em 0.8em; text-align: left; border-bottom: 1px solid #ddd; }
+th { background: #f0f0f0; }
+#results th { cursor: pointer; user-select: none; }
+#results th.asc::after { content: " \25B2"; }
+#results th.desc::after { content: " \25BC"; }
+#results td.num { text-align: right; }
+.params th { width: 12em; }
+.bar { background: #4a7bd0; height: 1em; }
+.histogram td { border: none; }
+input { padding: 0.3em; margin: 1em 0; width: 30em; }
+</style>
+</head>
+<body>
+<h1>GoFuzzy report</h1>
+
+<h2>Scan</h2>
+<table class="params">
+<tr><th>URL</th><td>{{.URL}}</td></tr>
+<tr><th>Method</th><td>{{.Method}}</td></tr>
+<tr><th>Mode</th><td>{{.Mode}}</td></tr>
+<tr><th>Wordlists</th><td>{{.Wordlists}}</td></tr>
+{{if .Extensions}}<tr><th>Extensions</th><td>{{.Extensions}}</td></tr>{{end}}
+{{if .CalibratedFilters}}<tr><th>Calibrated filters</th><td>{{.CalibratedFilters}}</td></tr>{{end}}
+{{if .Args}}<tr><th>Command line</th><td><code>gofuzzy {{.Args}}</code></td></tr>{{end}}
+</table>
+
+<h2>Summary</h2>
+<table class="params">
+<tr><th>Started</th><td>{{.Start}}</td></tr>
+<tr><th>State</th><td>{{.State}} after {{.Duration}}</td></tr>
+<tr><th>Requests</th><td>{{.NumDoneRequests}}</td></tr>
+<tr><th>Results</th><td>{{.NumResults}}</td></tr>
+</table>
+
+<h3>Status codes</h3>
+<table class="histogram">
+{{range .Codes}}<tr><td>{{.Code}}</td><td>{{.Count}}</td><td style="width: 20em"><div class="bar" style="width: {{.Percent}}%"></div></td></tr>
+{{end}}</table>
+
+<h2>Results</h2>
+<input id="filter" type="search" placeholder="Filter results, e.g. 200 or admin">
+<table id="results">
+<thead><tr>
+<th data-type="num">Chars</th><th data-type="num">Words</th><th data-type="num">Lines</th><th data-type="num">Header</th>
+<th data-type="num">Code</th><th data-type="num">Time (ms)</th><th>Payload</th><th>URL</th>
+</tr></thead>
+<tbody>
+{{range .Results}}<tr>
+<td class="num">{{.ContentLength}}</td><td class="num">{{.NumWords}}</td><td class="num">{{.NumLines}}</td><td class="num">{{.HeaderSize}}</td>
+<td class="num">{{.StatusCode}}</td><td class="num">{{.Duration}}</td><td>{{.PayloadString}}</td><td>{{if .StatusCode}}<a href="{{.URL}}">{{.URL}}</a>{{else}}{{.URL}}{{end}}</td>
+</tr>
+{{end}}</tbody>
+</table>
+
+<script>
+(function () {
+	var table = document.getElementById("results");
+	var body = table.tBodies[0];
+
+	document.getElementById("filter").addEventListener("input", function () {
+		var q = this.value.toLowerCase();
+		for (var i = 0; i < body.rows.length; i++) {
+			var row = body.rows[i];
+			row.style.display = row.textContent.toLowerCase().indexOf(q) === -1 ? "none" : "";
+		}
+	});
+
+	var headers = table.tHead.rows[0].cells;
+	for (var i = 0; i < headers.length; i++) {
+		headers[i].addEventListener("click", sortBy(i));
+	}
+
+	function sortBy(col) {
+		return function () {
+			var th = headers[col];
+			var asc = !th.classList.contains("asc");
+			for (var i = 0; i < headers.length; i++) {
+				headers[i].classList.remove("asc", "desc");
+			}
+			th.classList.add(asc ? "asc" : "desc");
+
+			var isNum = th.getAttribute("data-type") === "num";
+			var rows = Array.prototype.slice.call(body.rows);
+			rows.sort(function (a, b) {
+				var x = a.cells[col].textContent, y = b.cells[col].textContent;
+				var cmp = isNum ? x - y : x.localeCompare(y);
+				return asc ? cmp : -cmp;
+			});
+			rows.forEach(function (row) { body.appendChild(row); });
+		};
+	}
+})();
+</script>
+</body>
+</html>
+`))
@@ -10,19 +10,19 @@ import (
 )
 
 type json struct {
-	file io.Writer
+	file    io.Writer
+	results *[]*client.Result // Collected until the output is closed
 }
 
-var jsonResults []*client.Result
-
-func (json) write(r *client.Result) {
-	jsonResults = append(jsonResults, r)
+func (j json) write(r *client.Result) {
+	*j.results = append(*j.results, r)
 }
 
-func (j json) close() {
-	json, err := jsn.Marshal(jsonResults)
+func (j json) close(s *client.Summary) {
+	json, err := jsn.Marshal(*j.results)
 	if err != nil {
-		log.Fatal(err)
+		log.Printf("Unable to write the JSON output: %s", err)
+		return
 	}
 	fmt.Fprintln(j.file, string(json))
 }
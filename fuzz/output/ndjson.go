@@ -0,0 +1,88 @@
+package output
+
+import (
+	jsn "encoding/json"
+	"log"
+	"time"
+
+	"github.com/shellrausch/gofuzzy/fuzz/client"
+	"github.com/shellrausch/gofuzzy/fuzz/opts"
+)
+
+// ndjson writes one JSON object per line: a header record with the scan config, a record per
+// result as it arrives and a trailer record with the statistics. Every record is written to
+// the file at once, so the results so far are kept, also if the scan crashes.
+type ndjson struct {
+	enc *jsn.Encoder
+	opt *opts.Opts
+}
+
+type ndjsonHeader struct {
+	Type              string
+	Start             time.Time
+	Args              []string
+	URL               string
+	Method            string
+	Mode              string
+	Wordlists         []string
+	Extensions        []string
+	Concurrency       int
+	CalibratedFilters []string
+}
+
+type ndjsonResult struct {
+	Type string
+	*client.Result
+}
+
+type ndjsonTrailer struct {
+	Type            string
+	End             time.Time
+	NumDoneRequests uint
+	NumResults      uint
+	Duration        int64 // In milliseconds
+	Interrupted     bool
+}
+
+func (n ndjson) init() {
+	wordlists := []string{}
+	for _, wl := range n.opt.Wordlists {
+		wordlists = append(wordlists, wl.Name())
+	}
+
+	n.encode(ndjsonHeader{
+		Type:              "header",
+		Start:             time.Now(),
+		Args:              n.opt.Args,
+		URL:               n.opt.URL.String(),
+		Method:            n.opt.HTTPMethod,
+		Mode:              n.opt.Mode,
+		Wordlists:         wordlists,
+		Extensions:        n.opt.FileExtensions,
+		Concurrency:       n.opt.Concurrency,
+		CalibratedFilters: n.opt.CalibratedFilters,
+	})
+}
+
+func (n ndjson) write(r *client.Result) {
+	n.encode(ndjsonResult{Type: "result", Result: r})
+}
+
+func (n ndjson) close(s *client.Summary) {
+	n.encode(ndjsonTrailer{
+		Type:            "trailer",
+		End:             time.Now(),
+		NumDoneRequests: s.NumDoneRequests,
+		NumResults:      s.NumResults,
+		Duration:        int64(s.Duration / time.Millisecond),
+		Interrupted:     s.Interrupted,
+	})
+}
+
+func (n ndjson) encode(v interface{}) {
+	if err := n.enc.Encode(v); err != nil {
+		log.Printf("Unable to write the result: %s", err)
+	}
+}
+
+func (ndjson) writeProgress(p *client.Progress) {}
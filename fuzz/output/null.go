@@ -7,4 +7,4 @@ type null struct{}
 func (null) init()                            {}
 func (null) write(r *client.Result)           {}
 func (null) writeProgress(p *client.Progress) {}
-func (null) close()                           {}
+func (null) close(s *client.Summary)          {}
@@ -1,15 +1,24 @@
 package output
 
 import (
+	jsn "encoding/json"
+	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/shellrausch/gofuzzy/fuzz/client"
+	"github.com/shellrausch/gofuzzy/fuzz/opts"
 )
 
 // Output contains the output writers.
 type Output struct {
 	cliWriter  FuzzWriter
 	fileWriter FuzzWriter
+	saveWriter FuzzWriter
+	file       *os.File
 }
 
 // FuzzWriter must be implemented by every output format which wants to write.
@@ -17,44 +26,73 @@ type FuzzWriter interface {
 	init()
 	write(*client.Result)
 	writeProgress(*client.Progress)
-	close()
+	close(*client.Summary)
 }
 
 // SupportedFormats returns all available and supported output
 // formats to which gofuzzy can write to.
 func SupportedFormats() map[string]bool {
-	return map[string]bool{"csv": true, "txt": true, "json": true}
+	return map[string]bool{"csv": true, "txt": true, "json": true, "ndjson": true, "jsonl": true, "html": true, "sqlite": true, "har": true}
 }
 
 // New sets the output file and decides on which output media
 // the results should be shown. We always output on the CLI, also if another
 // output media is provided.
-func New(filename, outputFormat string) *Output {
-	f, _ := os.Create(filename)
+func New(opt *opts.Opts) (*Output, error) {
+	var saveWriter FuzzWriter = null{}
+	if opt.SaveDir != "" {
+		s, err := newSaveDir(opt.SaveDir)
+		if err != nil {
+			return nil, err
+		}
+		saveWriter = s
+	}
+
+	// The database is opened by the sqlite3 binary. With -append it must not be truncated.
+	var f *os.File
+	if opt.OutputFormat != "sqlite" && opt.OutputFile != "" {
+		var err error
+		if f, err = os.Create(opt.OutputFile); err != nil {
+			return nil, fmt.Errorf("Unable to create the output file: %s", err)
+		}
+	}
 
-	o := &Output{}
-	switch outputFormat {
+	o := &Output{file: f, saveWriter: saveWriter}
+	switch opt.OutputFormat {
 	case "csv":
 		o.fileWriter = csv{file: f}
 	case "txt":
 		o.fileWriter = txt{file: f}
 	case "json":
-		o.fileWriter = json{file: f}
+		o.fileWriter = json{file: f, results: &[]*client.Result{}}
+	case "ndjson", "jsonl":
+		o.fileWriter = ndjson{enc: jsn.NewEncoder(f), opt: opt}
+	case "html":
+		o.fileWriter = html{file: f, opt: opt, start: time.Now(), results: &[]*client.Result{}}
+	case "har":
+		o.fileWriter = har{file: f, numEntries: new(int)}
+	case "sqlite":
+		s, err := newSQLite(opt)
+		if err != nil {
+			return nil, err
+		}
+		o.fileWriter = s
 	default:
 		o.fileWriter = null{}
 	}
 	o.fileWriter.init()
 
 	// We write always to the CLI.
-	o.cliWriter = cli{}
+	o.cliWriter = cli{calibratedFilters: opt.CalibratedFilters, tableWriter: tabwriter.NewWriter(os.Stdout, 13, 0, 0, ' ', 0)}
 	o.cliWriter.init()
 
-	return o
+	return o, nil
 }
 
 // Write writes the result to the defined output and additionaly to the CLI.
 func (o *Output) Write(r *client.Result) {
 	o.fileWriter.write(r)
+	o.saveWriter.write(r)
 	o.cliWriter.write(r)
 }
 
@@ -64,7 +102,51 @@ func (o *Output) WriteProgress(pr *client.Progress) {
 	o.cliWriter.writeProgress(pr)
 }
 
-// Close closes output writer.
-func (o *Output) Close() {
-	o.fileWriter.close()
+// Close flushes all output writers with the summary of the scan and closes the output file.
+func (o *Output) Close(s *client.Summary) {
+	o.fileWriter.close(s)
+	o.saveWriter.close(s)
+	o.cliWriter.close(s)
+
+	if o.file != nil {
+		o.file.Close()
+	}
+}
+
+// payloadString formats the payloads of all keywords. A single payload is shown as it is,
+// prefixed with the path of a recursively discovered directory. Multiple payloads are
+// shown as sorted KEYWORD=payload pairs. An encoded payload follows in brackets.
+func payloadString(r *client.Result) string {
+	payload := r.Payload
+	if len(payload) == 1 {
+		for kw, p := range payload {
+			return r.BasePath + p + encodedString(r, kw)
+		}
+	}
+
+	pairs := []string{}
+	for kw, p := range payload {
+		pairs = append(pairs, kw+"="+p+encodedString(r, kw))
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, " ")
+}
+
+// recordsString formats the DNS records of a live host, if there are any.
+func recordsString(r *client.Result) string {
+	if len(r.Records) == 0 {
+		return ""
+	}
+
+	return " -> " + strings.Join(r.Records, ", ")
+}
+
+// encodedString formats the encoded payload of a keyword, if it differs from the raw payload.
+func encodedString(r *client.Result, kw string) string {
+	if enc, ok := r.Encoded[kw]; ok && enc != r.Payload[kw] {
+		return " [" + enc + "]"
+	}
+
+	return ""
 }
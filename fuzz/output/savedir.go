@@ -0,0 +1,89 @@
+package output
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/shellrausch/gofuzzy/fuzz/client"
+)
+
+// indexFile maps the payloads to the saved responses. It's in the same directory.
+const indexFile = "index.txt"
+
+// maxFileNameLength is the maximum length of the sanitized URL in a file name.
+const maxFileNameLength = 100
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// saveDir saves the raw response (status line, headers and body) of every result to a file
+// in a directory. The body is cut at -max-body. Every saved response is added to an index
+// file with its payload, so files and index entries of several scans can share a directory.
+type saveDir struct {
+	dir   string
+	index *os.File
+}
+
+func newSaveDir(dir string) (saveDir, error) {
+	index, err := os.OpenFile(filepath.Join(dir, indexFile), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return saveDir{}, fmt.Errorf("Unable to create the index of the saved responses: %s", err)
+	}
+
+	return saveDir{dir: dir, index: index}, nil
+}
+
+func (s saveDir) write(r *client.Result) {
+	// Results of a resumed scan were saved already, when they were found.
+	if r.Exchange == nil {
+		return
+	}
+
+	e := r.Exchange
+	payload := payloadString(r)
+	path := filepath.Join(s.dir, responseFileName(e.URL, payload))
+
+	raw := &bytes.Buffer{}
+	fmt.Fprintf(raw, "%s %s\r\n", e.ResponseProto, e.Status)
+	e.ResponseHeader.Write(raw)
+	raw.WriteString("\r\n")
+	raw.Write(e.ResponseBody)
+
+	if err := ioutil.WriteFile(path, raw.Bytes(), 0644); err != nil {
+		log.Printf("Unable to save the response: %s", err)
+		return
+	}
+
+	fmt.Fprintf(s.index, "%s\t%d\t%s\t%s\n", payload, r.StatusCode, e.URL, path)
+}
+
+func (s saveDir) close(sum *client.Summary) {
+	s.index.Close()
+}
+
+func (saveDir) init()                            {}
+func (saveDir) writeProgress(p *client.Progress) {}
+
+// responseFileName creates a file name from the URL without unsafe characters. A hash of
+// the URL and the payload makes it unique, also if the payload is not part of the URL.
+func responseFileName(url, payload string) string {
+	name := url
+	if i := strings.Index(name, "://"); i != -1 {
+		name = name[i+3:]
+	}
+
+	name = strings.Trim(unsafeFileNameChars.ReplaceAllString(name, "_"), "_.")
+	if len(name) > maxFileNameLength {
+		name = name[:maxFileNameLength]
+	}
+
+	hash := sha1.Sum([]byte(url + "\n" + payload))
+
+	return fmt.Sprintf("%s_%x.txt", name, hash[:5])
+}
@@ -0,0 +1,243 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shellrausch/gofuzzy/fuzz/client"
+	"github.com/shellrausch/gofuzzy/fuzz/opts"
+)
+
+const (
+	// sqliteBatchSize is the maximum number of results, which are committed at once.
+	sqliteBatchSize = 500
+	// sqliteBatchInterval is the interval in which pending results are committed.
+	sqliteBatchInterval = 2 * time.Second
+	// sqliteAck is printed by sqlite3 after every batch, which confirms that the batch is written.
+	sqliteAck = "gofuzzy-committed"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS scans (
+	id INTEGER PRIMARY KEY,
+	args TEXT,
+	url TEXT,
+	method TEXT,
+	mode TEXT,
+	wordlists TEXT,
+	extensions TEXT,
+	calibrated_filters TEXT,
+	start_time TEXT,
+	end_time TEXT,
+	num_requests INTEGER,
+	num_results INTEGER,
+	interrupted INTEGER
+);
+CREATE TABLE IF NOT EXISTS results (
+	id INTEGER PRIMARY KEY,
+	scan_id INTEGER REFERENCES scans(id),
+	url TEXT,
+	payload TEXT,
+	status_code INTEGER,
+	content_length INTEGER,
+	num_words INTEGER,
+	num_lines INTEGER,
+	header_size INTEGER,
+	ttfb_ms INTEGER,
+	duration_ms INTEGER,
+	records TEXT
+);
+CREATE INDEX IF NOT EXISTS results_scan_id ON results(scan_id);
+`
+
+// sqlite writes the scan and its results to a SQLite database with the sqlite3 binary,
+// which must be in the PATH. The results are committed in batches as they arrive.
+// Every batch is confirmed by sqlite3, so a failed batch is reported right away.
+// With -append the database is kept, so that several scans can be compared with SQL.
+type sqlite struct {
+	sync.Mutex
+	opt        *opts.Opts
+	cmd        *exec.Cmd
+	stdin      io.WriteCloser
+	w          *bufio.Writer
+	stdout     *bufio.Reader
+	stderr     bytes.Buffer // Read only after sqlite3 exited
+	numPending int
+	err        error // Set when sqlite3 failed. No more batches are sent then.
+	stop       chan bool
+}
+
+func newSQLite(opt *opts.Opts) (*sqlite, error) {
+	if !opt.Append {
+		os.Remove(opt.OutputFile)
+	}
+
+	s := &sqlite{opt: opt, cmd: exec.Command("sqlite3", "-batch", "-bail", opt.OutputFile), stop: make(chan bool)}
+	s.cmd.Stderr = &s.stderr
+	detach(s.cmd)
+
+	stdin, err := s.cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to start sqlite3 for the output: %s", err)
+	}
+	stdout, err := s.cmd.StdoutPipe()
+	if err == nil {
+		err = s.cmd.Start()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to start sqlite3 for the output: %s", err)
+	}
+	s.stdin, s.w, s.stdout = stdin, bufio.NewWriter(stdin), bufio.NewReader(stdout)
+
+	return s, nil
+}
+
+func (s *sqlite) init() {
+	wordlists := []string{}
+	for _, wl := range s.opt.Wordlists {
+		wordlists = append(wordlists, wl.Name())
+	}
+
+	s.exec(sqliteSchema)
+	s.exec(fmt.Sprintf("INSERT INTO scans (args, url, method, mode, wordlists, extensions, calibrated_filters, start_time) VALUES (%s, %s, %s, %s, %s, %s, %s, %s);",
+		sqlString(strings.Join(s.opt.Args, " ")),
+		sqlString(s.opt.URL.String()),
+		sqlString(s.opt.HTTPMethod),
+		sqlString(s.opt.Mode),
+		sqlString(strings.Join(wordlists, ", ")),
+		sqlString(strings.Join(s.opt.FileExtensions, ", ")),
+		sqlString(strings.Join(s.opt.CalibratedFilters, ", ")),
+		sqlString(time.Now().Format(time.RFC3339))))
+	// The id of the scan is kept for the results, since the last inserted id changes with every result.
+	s.exec("CREATE TEMP TABLE scan AS SELECT last_insert_rowid() AS id;")
+	s.exec("BEGIN;")
+	s.commit()
+
+	go s.commitPeriodically()
+}
+
+func (s *sqlite) write(r *client.Result) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.exec(fmt.Sprintf("INSERT INTO results (scan_id, url, payload, status_code, content_length, num_words, num_lines, header_size, ttfb_ms, duration_ms, records) VALUES ((SELECT id FROM scan), %s, %s, %d, %d, %d, %d, %d, %d, %d, %s);",
+		sqlString(r.URL), sqlString(payloadString(r)), r.StatusCode, r.ContentLength, r.NumWords, r.NumLines, r.HeaderSize, r.TTFB, r.Duration, sqlString(strings.Join(r.Records, ", "))))
+
+	s.numPending++
+	if s.numPending >= sqliteBatchSize {
+		s.exec("COMMIT;\nBEGIN;")
+		s.commit()
+	}
+}
+
+// commitPeriodically commits the pending results in an interval, so that they are in the
+// database also if no more results arrive. It returns when the output is closed.
+func (s *sqlite) commitPeriodically() {
+	ticker := time.NewTicker(sqliteBatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.Lock()
+			if s.numPending > 0 {
+				s.exec("COMMIT;\nBEGIN;")
+				s.commit()
+			}
+			s.Unlock()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *sqlite) close(sum *client.Summary) {
+	close(s.stop)
+	s.Lock()
+	defer s.Unlock()
+
+	// The failure of sqlite3 was reported already.
+	if s.err != nil {
+		return
+	}
+
+	interrupted := 0
+	if sum.Interrupted {
+		interrupted = 1
+	}
+
+	s.exec("COMMIT;")
+	s.exec(fmt.Sprintf("UPDATE scans SET end_time = %s, num_requests = %d, num_results = %d, interrupted = %d WHERE id = (SELECT id FROM scan);",
+		sqlString(time.Now().Format(time.RFC3339)), sum.NumDoneRequests, sum.NumResults, interrupted))
+	s.commit()
+	if s.err != nil {
+		return
+	}
+
+	s.stdin.Close()
+	if err := s.wait(); err != nil {
+		log.Printf("Unable to write the results to the database: %s", err)
+	}
+}
+
+// exec queues SQL statements, which are sent to sqlite3 with the next commit.
+func (s *sqlite) exec(sql string) {
+	fmt.Fprintln(s.w, sql)
+}
+
+// commit sends all queued statements to sqlite3 and waits until sqlite3 confirms them.
+// With -bail sqlite3 exits on the first failed statement, which is reported then.
+// The following results are not written anymore.
+func (s *sqlite) commit() {
+	s.numPending = 0
+	if s.err != nil {
+		return
+	}
+
+	s.exec("SELECT '" + sqliteAck + "';")
+	err := s.w.Flush()
+	for err == nil {
+		var line string
+		if line, err = s.stdout.ReadString('\n'); strings.TrimSpace(line) == sqliteAck {
+			return
+		}
+	}
+
+	s.stdin.Close()
+	if err = s.wait(); err == nil {
+		err = fmt.Errorf("sqlite3 exited")
+	}
+	s.err = err
+	log.Printf("Unable to write the results to the database, the following results are not saved: %s", s.err)
+}
+
+// wait waits until sqlite3 exited. The error contains the first line of the error message of sqlite3.
+func (s *sqlite) wait() error {
+	// All output must be read, before the pipes are closed by Wait.
+	io.Copy(ioutil.Discard, s.stdout)
+
+	err := s.cmd.Wait()
+	if msg := strings.SplitN(strings.TrimSpace(s.stderr.String()), "\n", 2)[0]; err != nil && msg != "" {
+		return fmt.Errorf("%s (%s)", msg, err)
+	}
+
+	return err
+}
+
+// sqlString quotes a string for SQL. It is passed hex encoded, so that any byte
+// in a payload (quotes, line breaks, NUL) is stored as it is.
+func sqlString(s string) string {
+	return "CAST(X'" + hex.EncodeToString([]byte(s)) + "' AS TEXT)"
+}
+
+func (*sqlite) writeProgress(p *client.Progress) {}
@@ -0,0 +1,15 @@
+//go:build !windows
+// +build !windows
+
+package output
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach starts a command in its own process group. Otherwise a Ctrl-C in the terminal
+// would kill it together with GoFuzzy, before all results are written.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
@@ -0,0 +1,12 @@
+package output
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach starts a command in its own process group. Otherwise a Ctrl-C in the console
+// would kill it together with GoFuzzy, before all results are written.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
@@ -12,14 +12,14 @@ type txt struct {
 }
 
 func (t txt) init() {
-	o := fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s", "Content-Length", "Words", "Lines", "Header", "Status-Code", "Payload")
+	o := fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s", "Content-Length", "Words", "Lines", "Header", "Status-Code", "TTFB-ms", "Duration-ms", "Payload")
 	fmt.Fprintln(t.file, o)
 }
 
 func (t txt) write(r *client.Result) {
-	o := fmt.Sprintf("%d\t\t\t\t%d\t\t%d\t\t%d\t\t%d\t\t\t%s", r.ContentLength, r.NumWords, r.NumLines, r.HeaderSize, r.StatusCode, r.Payload)
+	o := fmt.Sprintf("%d\t\t\t\t%d\t\t%d\t\t%d\t\t%d\t\t\t%d\t\t%d\t\t\t%s", r.ContentLength, r.NumWords, r.NumLines, r.HeaderSize, r.StatusCode, r.TTFB, r.Duration, payloadString(r)+recordsString(r))
 	fmt.Fprintln(t.file, o)
 }
 
 func (txt) writeProgress(p *client.Progress) {}
-func (txt) close()                           {}
+func (txt) close(s *client.Summary)          {}
@@ -0,0 +1,160 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/shellrausch/gofuzzy/fuzz/client"
+)
+
+// resultsSuffix is appended to the session file name for the file with the results so far.
+const resultsSuffix = ".results"
+
+// Session is the on-disk state of a scan, which allows to resume it
+// exactly where it stopped. The results are not kept in memory, they are
+// appended to a separate results file (one JSON object per line).
+type Session struct {
+	Args  []string            // Command line args of the scan
+	Bases []*client.BaseState // Progress of every base URL
+
+	resumed bool
+	results *os.File
+	w       *bufio.Writer
+	err     error // First error writing the results, reported by Save
+}
+
+// New creates a session for a scan with the given command line args.
+func New(args []string) *Session {
+	return &Session{Args: args}
+}
+
+// Load reads a session from a file.
+func Load(file string) (*Session, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read session: %s", err)
+	}
+
+	s := &Session{resumed: true}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("Unable to parse session '%s': %s", file, err)
+	}
+
+	return s, nil
+}
+
+// ReadResults passes the results of the session so far to fn, one after the other.
+// A session without results file has no results.
+func (s *Session) ReadResults(file string, fn func(*client.Result)) error {
+	if file == "" || !s.resumed {
+		return nil
+	}
+
+	f, err := os.Open(file + resultsSuffix)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to read session results: %s", err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	for dec.More() {
+		r := &client.Result{}
+		if err := dec.Decode(r); err != nil {
+			// The last result may be incomplete, if the scan was killed while writing it.
+			return fmt.Errorf("Unable to parse session results: %s", err)
+		}
+		fn(r)
+	}
+
+	return nil
+}
+
+// AddResult appends a result to the results file of the session. It is written to disk
+// with the next Save, which also reports errors.
+func (s *Session) AddResult(file string, r *client.Result) {
+	if file == "" || s.openResults(file) != nil {
+		return
+	}
+
+	b, err := json.Marshal(r)
+	if err == nil {
+		b = append(b, '\n')
+		_, err = s.w.Write(b)
+	}
+	if err != nil && s.err == nil {
+		s.err = err
+	}
+}
+
+// Save writes the session with the actual progress of the fuzzer to a file. The file is
+// replaced atomically, so an interrupted write doesn't destroy the last session.
+// The results are flushed before, so no result of a request which is done gets lost.
+func (s *Session) Save(file string, f *client.Fuzzer) error {
+	if file == "" {
+		return nil
+	}
+
+	if err := s.openResults(file); err == nil {
+		if err := s.w.Flush(); err != nil && s.err == nil {
+			s.err = err
+		}
+	}
+	if s.err != nil {
+		return fmt.Errorf("Unable to write session results: %s", s.err)
+	}
+
+	s.Bases = f.State()
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	tmp := file + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, file)
+}
+
+// Remove deletes the session file and its results, e.g. after the scan is complete.
+func (s *Session) Remove(file string) {
+	if file == "" {
+		return
+	}
+
+	if s.results != nil {
+		s.results.Close()
+	}
+	os.Remove(file)
+	os.Remove(file + resultsSuffix)
+}
+
+// openResults opens the results file once. A resumed session appends to its results,
+// a new session replaces the results of a former scan with the same session file.
+func (s *Session) openResults(file string) error {
+	if s.results != nil || s.err != nil {
+		return s.err
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if s.resumed {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+
+	results, err := os.OpenFile(file+resultsSuffix, flags, 0600)
+	if err != nil {
+		s.err = err
+		return err
+	}
+	s.results, s.w = results, bufio.NewWriter(results)
+
+	return nil
+}
@@ -1,12 +1,11 @@
 package utils
 
 import (
-	"bufio"
+	"crypto/rand"
 	"fmt"
-	"log"
+	"math"
 	"net/http"
 	"net/url"
-	"os"
 	"regexp"
 	"strconv"
 	"strings"
@@ -31,18 +30,23 @@ func NormalizeURL(u string) (*url.URL, error) {
 	return parsedURL, nil
 }
 
-// CountWordlistLines counts all lines in a file.
-func CountWordlistLines(file string) uint {
-	fh, _ := os.Open(file)
-	defer fh.Close()
+// ParseProxyURL parses and validates a proxy URL. Supported schemes are http, https and socks5.
+// An empty string results in a nil URL, which means no proxy.
+func ParseProxyURL(p string) (*url.URL, error) {
+	if p == "" {
+		return nil, nil
+	}
+
+	u, err := url.Parse(p)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse proxy URL: %s. %s", p, err)
+	}
 
-	s := bufio.NewScanner(fh)
-	var lc uint
-	for s.Scan() {
-		lc++
+	if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5" || u.Host == "" {
+		return nil, fmt.Errorf("Invalid proxy URL %s. Example: http://127.0.0.1:8080 or socks5://127.0.0.1:1080", p)
 	}
 
-	return lc
+	return u, nil
 }
 
 // CountWords counts all words for a given string. A word consists just of unicode letters.
@@ -101,7 +105,8 @@ func IsExtFormatValid(ext string) bool {
 	return true
 }
 
-// SplitHeaderFields splits header fields by a ":".
+// SplitHeaderFields splits header fields by a ":". Fields without a colon are skipped,
+// the header fields of the command line are validated beforehand.
 func SplitHeaderFields(h, sep string) map[string]string {
 	header := make(map[string]string)
 
@@ -114,7 +119,6 @@ func SplitHeaderFields(h, sep string) map[string]string {
 		sepIndex := strings.Index(h, ":")
 
 		if sepIndex == -1 {
-			log.Fatalln("Malformed header name/value. Missing separator colon ':', like name:value")
 			continue
 		}
 
@@ -126,9 +130,48 @@ func SplitHeaderFields(h, sep string) map[string]string {
 	return header
 }
 
-// MapSplit splits a string by separator, converts the tokens to an int and adds the converted token as a key to an map.
-func MapSplit(argval, sep string) map[int]bool {
-	return convertToIntMap(strings.Split(argval, sep))
+// Range is an inclusive range of integers.
+type Range struct {
+	Min int
+	Max int
+}
+
+// Ranges is a list of integer ranges. An integer is contained if it lies in one of the ranges.
+type Ranges []Range
+
+// Contains checks if an integer lies in one of the ranges.
+func (r Ranges) Contains(i int) bool {
+	for _, rg := range r {
+		if i >= rg.Min && i <= rg.Max {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Add adds a single integer.
+func (r *Ranges) Add(i int) {
+	*r = append(*r, Range{Min: i, Max: i})
+}
+
+// ParseRanges splits a string by separator and parses every token as a range.
+// Supported formats are: 200, 200-299, >1000, >=1000, <100 and <=100.
+func ParseRanges(argval, sep string) (Ranges, error) {
+	r := Ranges{}
+	if argval == "" {
+		return r, nil
+	}
+
+	for _, token := range strings.Split(argval, sep) {
+		rg, err := parseRange(strings.TrimSpace(token))
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, rg)
+	}
+
+	return r, nil
 }
 
 func isHTTPPrepended(hostname string) bool {
@@ -140,13 +183,48 @@ func prependHTTP(hostname string) string {
 	return "http://" + hostname
 }
 
-func convertToIntMap(arr []string) map[int]bool {
-	m := map[int]bool{}
-	for _, v := range arr {
-		if i, err := strconv.Atoi(v); err == nil {
-			m[i] = true
+func parseRange(token string) (Range, error) {
+	var err error
+	rg := Range{Min: math.MinInt32, Max: math.MaxInt32}
+
+	switch {
+	case strings.HasPrefix(token, ">="):
+		rg.Min, err = strconv.Atoi(token[2:])
+	case strings.HasPrefix(token, ">"):
+		rg.Min, err = strconv.Atoi(token[1:])
+		rg.Min++
+	case strings.HasPrefix(token, "<="):
+		rg.Max, err = strconv.Atoi(token[2:])
+	case strings.HasPrefix(token, "<"):
+		rg.Max, err = strconv.Atoi(token[1:])
+		rg.Max--
+	case strings.Contains(token, "-"):
+		bounds := strings.SplitN(token, "-", 2)
+		if rg.Min, err = strconv.Atoi(bounds[0]); err == nil {
+			rg.Max, err = strconv.Atoi(bounds[1])
 		}
+	default:
+		rg.Min, err = strconv.Atoi(token)
+		rg.Max = rg.Min
+	}
+
+	if err != nil || rg.Min > rg.Max {
+		return rg, fmt.Errorf("Invalid value or range '%s'. Examples: 200, 200-299, >1000, <100", token)
+	}
+
+	return rg, nil
+}
+
+// RandomString creates a random string of n lowercase letters. Without digits or other
+// characters the string is always counted as one word.
+func RandomString(n int) string {
+	const letters = "abcdefghijklmnopqrstuvwxyz"
+
+	b := make([]byte, n)
+	rand.Read(b)
+	for i := range b {
+		b[i] = letters[int(b[i])%len(letters)]
 	}
 
-	return m
+	return string(b)
 }
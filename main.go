@@ -1,32 +1,116 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/shellrausch/gofuzzy/fuzz/client"
 	"github.com/shellrausch/gofuzzy/fuzz/opts"
 	"github.com/shellrausch/gofuzzy/fuzz/output"
+	"github.com/shellrausch/gofuzzy/fuzz/session"
 )
 
 func main() {
 	opt := opts.New()
 	if err := opt.Parse(output.SupportedFormats()); err != nil {
+		exit(err)
+	}
+
+	sess := session.New(os.Args[1:])
+
+	if opt.ResumeFile != "" {
+		var err error
+		if sess, err = session.Load(opt.ResumeFile); err != nil {
+			log.Fatal(err)
+		}
+
+		resumeFile := opt.ResumeFile
+		opt = opts.New()
+		if err := opt.ParseArgs(output.SupportedFormats(), sess.Args); err != nil {
+			exit(err)
+		}
+		opt.SessionFile = resumeFile
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		// Stop producing and cancel all requests in flight, also during the calibration. The
+		// client finishes gracefully, so all results so far are flushed to the outputs.
+		<-sigs
+		cancel()
+		signal.Stop(sigs)
+	}()
+
+	fuzzer := client.New(opt)
+	fuzzer.Resume(sess.Bases)
+	fuzzer.HandleRateSignals()
+	fuzzer.Calibrate(ctx)
+
+	out, err := output.New(opt)
+	if err != nil {
 		log.Fatal(err)
 	}
-	out := output.New(opt.OutputFile, opt.OutputFormat)
+	if err := sess.ReadResults(opt.SessionFile, out.Write); err != nil {
+		log.Print(err)
+	}
 
-	chans := client.New(opt)
-	go client.Start(opt)
+	go fuzzer.Run(ctx)
+
+	saveTick := time.Tick(time.Millisecond * time.Duration(opt.SessionSaveInterval))
 
 	for {
 		select {
-		case r := <-chans.Result:
+		case r, open := <-fuzzer.Result:
+			if !open {
+				// The result channel is closed after the summary was sent. This way all
+				// results are written, before the outputs are closed.
+				finish(<-fuzzer.Finish, out, sess, fuzzer, opt.SessionFile)
+				return
+			}
 			out.Write(r)
-		case p := <-chans.Progress:
+			sess.AddResult(opt.SessionFile, r)
+		case p := <-fuzzer.Progress:
 			go out.WriteProgress(p)
-		case <-chans.Finish:
-			out.Close()
-			return
+		case <-saveTick:
+			if err := sess.Save(opt.SessionFile, fuzzer); err != nil {
+				log.Printf("Unable to save session: %s", err)
+			}
 		}
 	}
 }
+
+// finish closes the outputs. The session of an interrupted scan is saved, otherwise it is removed.
+func finish(s *client.Summary, out *output.Output, sess *session.Session, fuzzer *client.Fuzzer, sessionFile string) {
+	out.Close(s)
+
+	if !s.Interrupted {
+		sess.Remove(sessionFile)
+		return
+	}
+
+	if err := sess.Save(sessionFile, fuzzer); err != nil {
+		log.Fatalf("Unable to save session: %s", err)
+	}
+	if sessionFile != "" {
+		log.Printf("Session saved. Resume with: gofuzzy -resume %s", sessionFile)
+	}
+	os.Exit(130)
+}
+
+// exit terminates on an invalid command line. Requesting the help is not an error.
+func exit(err error) {
+	if err == flag.ErrHelp {
+		os.Exit(0)
+	}
+
+	log.Fatal(err)
+}